@@ -0,0 +1,326 @@
+package ffcookies
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ReadArchive reads the cookies for the host from a Firefox profile backup
+// archive (a .zip, .tar, or .tar.gz file). The profile's cookie database is
+// located in the archive as follows:
+//
+//   - the profile (set by [WithProfile], or the default profile) of an
+//     archived Firefox profile directory containing profiles.ini
+//   - the archived profile directory with the profile's name or directory name
+//   - the only cookie database in the archive, or the most recently modified
+//     cookie database when the archive contains multiple profiles
+//
+// The database is read as with [ReadFS].
+func ReadArchive(ctx context.Context, name, host string, opts ...Option) ([]*http.Cookie, error) {
+	o := newOptions(opts...)
+	fsys, closeArchive, err := openArchive(name)
+	if err != nil {
+		return nil, err
+	}
+	defer closeArchive()
+	file, err := archiveCookiePath(fsys, o.profile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	o.logger.Debug("resolved archived cookie file", "archive", name, "path", file)
+	src, closeSrc, err := o.openFS(ctx, fsys, file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	defer closeSrc()
+	return o.read(ctx, src, file, host)
+}
+
+// openArchive opens the archive as a file system, returning the file system
+// and a func closing it. The archive type is determined by its contents.
+func openArchive(name string) (fs.FS, func() error, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	// check magic
+	buf := make([]byte, 262)
+	n, _ := io.ReadFull(f, buf)
+	buf = buf[:n]
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	var fsys fs.FS
+	switch {
+	case bytes.HasPrefix(buf, []byte("PK\x03\x04")), bytes.HasPrefix(buf, []byte("PK\x05\x06")):
+		zr, err := zip.NewReader(f, fi.Size())
+		if err != nil {
+			_ = f.Close()
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return zr, f.Close, nil
+	case bytes.HasPrefix(buf, []byte("\x1f\x8b")):
+		var gz *gzip.Reader
+		if gz, err = gzip.NewReader(f); err == nil {
+			fsys, err = readTar(gz)
+		}
+	case len(buf) > 261 && string(buf[257:262]) == "ustar":
+		fsys, err = readTar(f)
+	default:
+		err = ErrUnsupportedArchive
+	}
+	_ = f.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return fsys, func() error { return nil }, nil
+}
+
+// archiveCookiePath returns the path of the cookie database for the profile
+// in the archive's file system. See [ReadArchive].
+func archiveCookiePath(fsys fs.FS, profile string) (string, error) {
+	// find base profile directories and profile directories
+	var dirs, paths []string
+	if err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case d.IsDir():
+		case d.Name() == "profiles.ini":
+			dirs = append(dirs, path.Dir(name))
+		case d.Name() == "cookies.sqlite":
+			paths = append(paths, path.Dir(name))
+		}
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	pfs := profileFS{fsys: fsys}
+	for _, dir := range dirs {
+		if p, err := pfs.profilePath(dir, profile); err == nil && slices.Contains(paths, p) {
+			return pfs.join(p, "cookies.sqlite"), nil
+		}
+	}
+	if profile != "" {
+		paths = slices.DeleteFunc(paths, func(p string) bool {
+			base := path.Base(p)
+			_, name, _ := strings.Cut(base, ".")
+			return base != profile && name != profile
+		})
+		if len(paths) == 0 {
+			return "", fmt.Errorf("%w: %s", ErrProfileNotFound, profile)
+		}
+	}
+	switch len(paths) {
+	case 0:
+		return "", ErrCookieDBNotFound
+	case 1:
+		return pfs.join(paths[0], "cookies.sqlite"), nil
+	}
+	p, ambiguous := pfs.newestCookies(paths)
+	if p == "" || ambiguous {
+		return "", fmt.Errorf("%w: ambiguous profile: %s", ErrProfileNotFound, strings.Join(paths, ", "))
+	}
+	return pfs.join(p, "cookies.sqlite"), nil
+}
+
+// archiveFiles are the names of the files whose contents are kept when
+// reading a tar archive. Only the names and sizes of other files are kept,
+// as profile backups can be large.
+var archiveFiles = []string{
+	"profiles.ini",
+	"installs.ini",
+	"containers.json",
+	"cookies.sqlite",
+	"cookies.sqlite-wal",
+}
+
+// readTar reads a tar archive as a file system.
+func readTar(r io.Reader) (fs.FS, error) {
+	fsys := make(memFS)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		switch {
+		case err == io.EOF:
+			return fsys, nil
+		case err != nil:
+			return nil, err
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		if !fs.ValidPath(name) || name == "." {
+			continue
+		}
+		f := &memFile{
+			name:    path.Base(name),
+			size:    hdr.Size,
+			mode:    hdr.FileInfo().Mode(),
+			modTime: hdr.ModTime,
+		}
+		if hdr.Typeflag == tar.TypeReg && slices.Contains(archiveFiles, f.name) {
+			if f.data, err = io.ReadAll(tr); err != nil {
+				return nil, err
+			}
+		}
+		fsys[name] = f
+	}
+}
+
+// memFS is a file system of files in memory, keyed by path. Parent
+// directories not in the file system are synthesized.
+type memFS map[string]*memFile
+
+// Open satisfies the [fs.FS] interface.
+func (fsys memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	f := fsys[name]
+	if f != nil && !f.mode.IsDir() {
+		return &memReader{memFile: f, Reader: bytes.NewReader(f.data)}, nil
+	}
+	// directory
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+	entries := make(map[string]fs.DirEntry)
+	for p, child := range fsys {
+		rest, ok := strings.CutPrefix(p, prefix)
+		if !ok || rest == "" {
+			continue
+		}
+		if dir, _, ok := strings.Cut(rest, "/"); ok {
+			if entries[dir] == nil {
+				entries[dir] = fs.FileInfoToDirEntry(&memFile{name: dir, mode: fs.ModeDir | 0o755})
+			}
+		} else {
+			entries[rest] = fs.FileInfoToDirEntry(child)
+		}
+	}
+	if f == nil && name != "." && len(entries) == 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if f == nil {
+		f = &memFile{name: path.Base(name), mode: fs.ModeDir | 0o755}
+	}
+	d := &memDir{memFile: f}
+	for _, entry := range entries {
+		d.entries = append(d.entries, entry)
+	}
+	slices.SortFunc(d.entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return d, nil
+}
+
+// memFile is a file in memory.
+type memFile struct {
+	name    string
+	data    []byte
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// Name satisfies the [fs.FileInfo] interface.
+func (f *memFile) Name() string {
+	return f.name
+}
+
+// Size satisfies the [fs.FileInfo] interface.
+func (f *memFile) Size() int64 {
+	return f.size
+}
+
+// Mode satisfies the [fs.FileInfo] interface.
+func (f *memFile) Mode() fs.FileMode {
+	return f.mode
+}
+
+// ModTime satisfies the [fs.FileInfo] interface.
+func (f *memFile) ModTime() time.Time {
+	return f.modTime
+}
+
+// IsDir satisfies the [fs.FileInfo] interface.
+func (f *memFile) IsDir() bool {
+	return f.mode.IsDir()
+}
+
+// Sys satisfies the [fs.FileInfo] interface.
+func (f *memFile) Sys() any {
+	return nil
+}
+
+// memReader is an open file in memory. Reads return the file's contents
+// when kept (see archiveFiles), and are otherwise empty.
+type memReader struct {
+	*memFile
+	*bytes.Reader
+}
+
+// Stat satisfies the [fs.File] interface.
+func (f *memReader) Stat() (fs.FileInfo, error) {
+	return f.memFile, nil
+}
+
+// Close satisfies the [fs.File] interface.
+func (f *memReader) Close() error {
+	return nil
+}
+
+// memDir is an open directory in memory.
+type memDir struct {
+	*memFile
+	entries []fs.DirEntry
+}
+
+// Stat satisfies the [fs.File] interface.
+func (d *memDir) Stat() (fs.FileInfo, error) {
+	return d.memFile, nil
+}
+
+// Read satisfies the [fs.File] interface.
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+// Close satisfies the [fs.File] interface.
+func (d *memDir) Close() error {
+	return nil
+}
+
+// ReadDir satisfies the [fs.ReadDirFile] interface.
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
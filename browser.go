@@ -0,0 +1,292 @@
+package ffcookies
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Browser is a Firefox based browser (or other Gecko based application),
+// whose profiles use the Firefox profile layout and cookie database. See
+// [WithBrowser].
+type Browser int
+
+// Browser values.
+const (
+	// Firefox is Mozilla Firefox.
+	Firefox Browser = iota
+	// TorBrowser is the Tor Browser. Tor Browser is not installed, and keeps
+	// its profile (profile.default) in the directory it was extracted to, so
+	// the common extraction and torbrowser-launcher directories are checked.
+	TorBrowser
+	// LibreWolf is LibreWolf.
+	LibreWolf
+	// Waterfox is Waterfox.
+	Waterfox
+	// Floorp is Floorp.
+	Floorp
+	// MullvadBrowser is the Mullvad Browser. Like [TorBrowser], the
+	// extraction directories of the portable release are checked, in addition
+	// to the directories of the installed release.
+	MullvadBrowser
+	// Thunderbird is Mozilla Thunderbird, which stores the cookies of its
+	// web content (such as OAuth logins and feeds) in its profiles.
+	Thunderbird
+	// SeaMonkey is SeaMonkey. SeaMonkey uses an older cookie database
+	// schema, which is read the same as legacy Firefox cookie databases.
+	SeaMonkey
+	// PaleMoon is Pale Moon. Like [SeaMonkey], Pale Moon uses an older cookie
+	// database schema.
+	PaleMoon
+)
+
+// String satisfies the fmt.Stringer interface.
+func (b Browser) String() string {
+	switch b {
+	case Firefox:
+		return "Firefox"
+	case TorBrowser:
+		return "Tor Browser"
+	case LibreWolf:
+		return "LibreWolf"
+	case Waterfox:
+		return "Waterfox"
+	case Floorp:
+		return "Floorp"
+	case MullvadBrowser:
+		return "Mullvad Browser"
+	case Thunderbird:
+		return "Thunderbird"
+	case SeaMonkey:
+		return "SeaMonkey"
+	case PaleMoon:
+		return "Pale Moon"
+	}
+	return fmt.Sprintf("Browser(%d)", int(b))
+}
+
+// Placeholders for the %APPDATA% and %LOCALAPPDATA% directories in
+// browserDirs.
+const (
+	appData      = "%APPDATA%"
+	localAppData = "%LOCALAPPDATA%"
+)
+
+// torBrowserData is the profile directory of a Tor Browser (or Mullvad
+// Browser) install, relative to the install directory.
+var torBrowserData = []string{"Browser", "TorBrowser", "Data", "Browser"}
+
+// browserDirs are the candidate base profile directories of each browser for
+// each platform (windows, darwin, and other platforms), relative to the
+// user's home directory, %APPDATA%, or %LOCALAPPDATA%.
+var browserDirs = map[Browser]map[string][][]string{
+	Firefox: {
+		"windows": {
+			{appData, "Mozilla", "Firefox"},
+		},
+		"darwin": {
+			{"Library", "Application Support", "Firefox"},
+		},
+		"": {
+			{".mozilla", "firefox"},
+			{".mozilla", "Firefox"},
+			{".Mozilla", "firefox"},
+			{".Mozilla", "Firefox"},
+			{"snap", "firefox", "common", ".mozilla", "firefox"},
+			{".var", "app", "org.mozilla.firefox", ".mozilla", "firefox"},
+		},
+	},
+	TorBrowser: {
+		"windows": {
+			append([]string{"Desktop", "Tor Browser"}, torBrowserData...),
+			append([]string{"OneDrive", "Desktop", "Tor Browser"}, torBrowserData...),
+		},
+		"darwin": {
+			{"Library", "Application Support", "TorBrowser-Data", "Browser"},
+		},
+		"": {
+			append([]string{".local", "share", "torbrowser", "tbb", "x86_64", "tor-browser"}, torBrowserData...),
+			append([]string{".local", "share", "torbrowser", "tbb", "x86_64", "tor-browser_en-US"}, torBrowserData...),
+			append([]string{".var", "app", "org.torproject.torbrowser-launcher", "data", "torbrowser", "tbb", "x86_64", "tor-browser"}, torBrowserData...),
+			append([]string{"tor-browser"}, torBrowserData...),
+			append([]string{"tor-browser_en-US"}, torBrowserData...),
+			append([]string{"Desktop", "tor-browser"}, torBrowserData...),
+			append([]string{"Downloads", "tor-browser"}, torBrowserData...),
+		},
+	},
+	LibreWolf: {
+		"windows": {
+			{appData, "librewolf"},
+		},
+		"darwin": {
+			{"Library", "Application Support", "librewolf"},
+		},
+		"": {
+			{".librewolf"},
+			{".var", "app", "io.gitlab.librewolf-community", ".librewolf"},
+		},
+	},
+	Waterfox: {
+		"windows": {
+			{appData, "Waterfox"},
+		},
+		"darwin": {
+			{"Library", "Application Support", "Waterfox"},
+		},
+		"": {
+			{".waterfox"},
+			{".var", "app", "net.waterfox.waterfox", ".waterfox"},
+		},
+	},
+	Floorp: {
+		"windows": {
+			{appData, "Floorp"},
+		},
+		"darwin": {
+			{"Library", "Application Support", "Floorp"},
+		},
+		"": {
+			{".floorp"},
+			{".var", "app", "one.ablaze.floorp", ".floorp"},
+		},
+	},
+	MullvadBrowser: {
+		"windows": {
+			{appData, "Mullvad", "MullvadBrowser"},
+			append([]string{localAppData, "Mullvad", "MullvadBrowser", "Release"}, torBrowserData...),
+			append([]string{"Desktop", "Mullvad Browser"}, torBrowserData...),
+		},
+		"darwin": {
+			{"Library", "Application Support", "MullvadBrowser", "Browser"},
+			{"Library", "Application Support", "MullvadBrowser-Data", "Browser"},
+		},
+		"": {
+			{".mullvad", "mullvadbrowser"},
+			{".var", "app", "net.mullvad.MullvadBrowser", ".mullvad", "mullvadbrowser"},
+			append([]string{"mullvad-browser"}, torBrowserData...),
+			append([]string{"Desktop", "mullvad-browser"}, torBrowserData...),
+			append([]string{"Downloads", "mullvad-browser"}, torBrowserData...),
+		},
+	},
+	Thunderbird: {
+		"windows": {
+			{appData, "Thunderbird"},
+		},
+		"darwin": {
+			{"Library", "Thunderbird"},
+		},
+		"": {
+			{".thunderbird"},
+			{"snap", "thunderbird", "common", ".thunderbird"},
+			{".var", "app", "org.mozilla.Thunderbird", ".thunderbird"},
+		},
+	},
+	SeaMonkey: {
+		"windows": {
+			{appData, "Mozilla", "SeaMonkey"},
+		},
+		"darwin": {
+			{"Library", "Application Support", "SeaMonkey"},
+		},
+		"": {
+			{".mozilla", "seamonkey"},
+		},
+	},
+	PaleMoon: {
+		"windows": {
+			{appData, "Moonchild Productions", "Pale Moon"},
+		},
+		"darwin": {
+			{"Library", "Application Support", "Pale Moon"},
+		},
+		"": {
+			{".moonchild productions", "pale moon"},
+			{".var", "app", "org.palemoon.PaleMoon", ".moonchild productions", "pale moon"},
+		},
+	},
+}
+
+// ProfileDirs returns the candidate base profile directories for the browser
+// on the current platform, in order of precedence. See [ProfileDirs] for the
+// Firefox directories.
+func (b Browser) ProfileDirs() ([]string, error) {
+	return b.dirs(baseDir)
+}
+
+// dirs returns the candidate base profile directories for the browser on the
+// current platform, using base to resolve the base directory for the first
+// element of each path in browserDirs.
+func (b Browser) dirs(base func(string) (string, error)) ([]string, error) {
+	platforms, ok := browserDirs[b]
+	if !ok {
+		return nil, fmt.Errorf("unknown browser %s", b)
+	}
+	paths, ok := platforms[runtime.GOOS]
+	if !ok {
+		paths = platforms[""]
+	}
+	bases := make(map[string]string)
+	var dirs []string
+	for _, path := range paths {
+		dir, ok := bases[path[0]]
+		if !ok {
+			var err error
+			if dir, err = base(path[0]); err != nil {
+				return nil, err
+			}
+			bases[path[0]] = dir
+		}
+		if path[0] != appData && path[0] != localAppData {
+			dir = filepath.Join(dir, path[0])
+		}
+		dirs = append(dirs, filepath.Join(append([]string{dir}, path[1:]...)...))
+	}
+	return dirs, nil
+}
+
+// ProfileDir returns the base profile directory for the browser (the
+// directory containing profiles.ini, or the browser's profile directories)
+// from the platform's candidate directories. When multiple candidate
+// directories exist, the one with the most recently modified cookie database
+// is used. See [Browser.ProfileDirs].
+func (b Browser) ProfileDir() (string, error) {
+	dirs, err := b.ProfileDirs()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine the %s profile directory: %w", strings.ToLower(b.String()), err)
+	}
+	var found []string
+	for _, dir := range dirs {
+		if osFS.isDir(dir) {
+			found = append(found, dir)
+		}
+	}
+	switch {
+	case len(found) == 0 && b == Firefox:
+		return "", fmt.Errorf("%w (tried %s)", ErrProfileDirNotFound, strings.Join(dirs, ", "))
+	case len(found) == 0:
+		return "", fmt.Errorf("%s: %w (tried %s)", b, ErrProfileDirNotFound, strings.Join(dirs, ", "))
+	case len(found) == 1:
+		return found[0], nil
+	}
+	return newestDir(found), nil
+}
+
+// baseDir returns the base directory for the first element of a path in
+// browserDirs.
+func baseDir(elem string) (string, error) {
+	switch elem {
+	case appData:
+		if dir := os.Getenv("APPDATA"); dir != "" {
+			return dir, nil
+		}
+		return os.UserConfigDir()
+	case localAppData:
+		if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+			return dir, nil
+		}
+		return os.UserCacheDir()
+	}
+	return os.UserHomeDir()
+}
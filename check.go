@@ -0,0 +1,161 @@
+package ffcookies
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/kenshaw/ffcookies/internal/sqlite"
+	"github.com/kenshaw/ffcookies/models"
+)
+
+// DatabaseReport is the result of checking a cookie database. See
+// [ValidateDatabase].
+type DatabaseReport struct {
+	// File is the checked sqlite3 file.
+	File string `json:"file"`
+	// Integrity are the problems reported by the integrity check. When read
+	// without a sqlite3 driver, only the moz_cookies table is checked, by
+	// reading all of its rows.
+	Integrity []string `json:"integrity,omitempty"`
+	// NoTable is true when the database does not have a moz_cookies table.
+	NoTable bool `json:"no_table,omitempty"`
+	// Schema is the schema of the moz_cookies table.
+	Schema *models.Schema `json:"schema,omitempty"`
+	// Missing are the expected moz_cookies columns missing from the schema.
+	// Missing columns are read as their default values.
+	Missing []string `json:"missing,omitempty"`
+	// Extra are the moz_cookies columns not read by this package.
+	Extra []string `json:"extra,omitempty"`
+}
+
+// OK returns true when the database passed the integrity check and has a
+// moz_cookies table. Missing and extra columns do not prevent cookies from
+// being read.
+func (r *DatabaseReport) OK() bool {
+	return len(r.Integrity) == 0 && !r.NoTable
+}
+
+// Err returns a [CorruptError] describing the problems found, or nil when the
+// database is OK.
+func (r *DatabaseReport) Err() error {
+	var errs []string
+	if r.NoTable {
+		errs = append(errs, "no such table: moz_cookies")
+	}
+	errs = append(errs, r.Integrity...)
+	if len(errs) == 0 {
+		return nil
+	}
+	return &CorruptError{
+		File: dbPath(r.File),
+		Err:  errors.New(strings.Join(errs, "; ")),
+	}
+}
+
+// ValidateDatabase checks the cookie database in the provided sqlite3 file,
+// running an integrity check and verifying the moz_cookies table and its
+// columns. Useful before trusting the cookies read from copied or partially
+// synced profiles.
+//
+// Problems found, including files that are not sqlite3 databases, are
+// reported in the returned report. An error is returned only when the file
+// cannot be read.
+func ValidateDatabase(ctx context.Context, file string, opts ...Option) (*DatabaseReport, error) {
+	if err := dbExists(file); err != nil {
+		return nil, err
+	}
+	o := newOptions(opts...)
+	r := &DatabaseReport{
+		File: file,
+	}
+	var err error
+	if o.driver == "" && driverName() == "" {
+		err = r.checkNative(ctx, file)
+	} else {
+		err = r.checkDB(ctx, o, file)
+	}
+	var corruptErr *CorruptError
+	switch {
+	case errors.As(err, &corruptErr):
+		// report the underlying error
+		r.Integrity = append(r.Integrity, corruptErr.Err.Error())
+	case err != nil:
+		return nil, err
+	}
+	if r.Schema != nil {
+		r.Missing, r.Extra = r.Schema.Missing(), r.Schema.Extra()
+	}
+	return r, nil
+}
+
+// checkDB checks the database using a sqlite3 driver. The database is opened
+// read-only and immutable, so that the check does not lock the database or
+// create journal files.
+func (r *DatabaseReport) checkDB(ctx context.Context, o *options, file string) error {
+	db, err := o.openDB(ctx, fileURI(dbPath(file), "?mode=ro&immutable=1"))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	// integrity check
+	if err := r.integrityCheck(ctx, db); err != nil {
+		return dbError(file, err)
+	}
+	// table
+	var name string
+	switch err := db.QueryRowContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'moz_cookies'`).Scan(&name); {
+	case errors.Is(err, sql.ErrNoRows):
+		r.NoTable = true
+		return nil
+	case err != nil:
+		return dbError(file, err)
+	}
+	if r.Schema, err = models.ReadSchema(ctx, db); err != nil {
+		return dbError(file, err)
+	}
+	return nil
+}
+
+// integrityCheck runs the integrity check, adding the problems found.
+func (r *DatabaseReport) integrityCheck(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, `PRAGMA integrity_check`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			return err
+		}
+		if msg != "ok" {
+			r.Integrity = append(r.Integrity, msg)
+		}
+	}
+	return rows.Err()
+}
+
+// checkNative checks the database without a sqlite3 driver.
+func (r *DatabaseReport) checkNative(ctx context.Context, file string) error {
+	db, err := sqlite.Open(dbPath(file))
+	if err != nil {
+		return dbError(file, err)
+	}
+	t, err := db.Table("moz_cookies")
+	switch {
+	case errors.Is(err, sqlite.ErrNoTable):
+		r.NoTable = true
+		return nil
+	case err != nil:
+		return dbError(file, err)
+	}
+	r.Schema = &models.Schema{
+		Version: db.UserVersion(),
+		Columns: t.Columns,
+	}
+	return dbError(file, t.Scan(func(int64, []any) error {
+		return ctx.Err()
+	}))
+}
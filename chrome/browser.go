@@ -0,0 +1,216 @@
+package chrome
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Browser is a Chromium based browser. See [WithBrowser].
+type Browser int
+
+// Browser values.
+const (
+	// Chrome is Google Chrome, registered as the "chrome" cookie source.
+	Chrome Browser = iota
+	// Chromium is Chromium, registered as the "chromium" cookie source.
+	Chromium
+	// Brave is Brave, registered as the "brave" cookie source.
+	Brave
+	// Edge is Microsoft Edge, registered as the "edge" cookie source.
+	Edge
+	// Vivaldi is Vivaldi, registered as the "vivaldi" cookie source.
+	Vivaldi
+	// Opera is Opera, registered as the "opera" cookie source. Opera's user
+	// data directory is its default profile.
+	Opera
+)
+
+// browsers are the registered browsers.
+var browsers = []Browser{
+	Chrome,
+	Chromium,
+	Brave,
+	Edge,
+	Vivaldi,
+	Opera,
+}
+
+// String satisfies the fmt.Stringer interface, returning the browser's
+// registered cookie source name.
+func (b Browser) String() string {
+	if info, ok := browserInfos[b]; ok {
+		return info.name
+	}
+	return fmt.Sprintf("Browser(%d)", int(b))
+}
+
+// Placeholders for the first element of a path in browserInfo.dirs. Paths
+// without a placeholder are relative to the user's configuration directory,
+// or %LOCALAPPDATA% on Windows.
+const (
+	home    = "~"
+	appData = "%APPDATA%"
+)
+
+// browserInfo is the information for a browser.
+type browserInfo struct {
+	// name is the registered cookie source name.
+	name string
+	// dirs are the candidate user data directories for each platform
+	// (windows, darwin, and other platforms).
+	dirs map[string][][]string
+	// storage and account are the keyring service and account names of the
+	// Safe Storage password.
+	storage, account string
+	// application is the libsecret application attribute, and folder the
+	// KWallet folder, of the Safe Storage password on Linux.
+	application, folder string
+}
+
+// browserInfos are the browsers' information.
+var browserInfos = map[Browser]browserInfo{
+	Chrome: {
+		name: "chrome",
+		dirs: map[string][][]string{
+			"windows": {{"Google", "Chrome", "User Data"}},
+			"darwin":  {{"Google", "Chrome"}},
+			"":        {{"google-chrome"}},
+		},
+		storage:     "Chrome Safe Storage",
+		account:     "Chrome",
+		application: "chrome",
+		folder:      "Chrome Keys",
+	},
+	Chromium: {
+		name: "chromium",
+		dirs: map[string][][]string{
+			"windows": {{"Chromium", "User Data"}},
+			"darwin":  {{"Chromium"}},
+			"": {
+				{"chromium"},
+				{home, "snap", "chromium", "common", "chromium"},
+				{home, ".var", "app", "org.chromium.Chromium", "config", "chromium"},
+			},
+		},
+		storage:     "Chromium Safe Storage",
+		account:     "Chromium",
+		application: "chromium",
+		folder:      "Chromium Keys",
+	},
+	Brave: {
+		name: "brave",
+		dirs: map[string][][]string{
+			"windows": {{"BraveSoftware", "Brave-Browser", "User Data"}},
+			"darwin":  {{"BraveSoftware", "Brave-Browser"}},
+			"": {
+				{"BraveSoftware", "Brave-Browser"},
+				{home, "snap", "brave", "current", ".config", "BraveSoftware", "Brave-Browser"},
+				{home, ".var", "app", "com.brave.Browser", "config", "BraveSoftware", "Brave-Browser"},
+			},
+		},
+		storage:     "Brave Safe Storage",
+		account:     "Brave",
+		application: "brave",
+		folder:      "Brave Keys",
+	},
+	Edge: {
+		name: "edge",
+		dirs: map[string][][]string{
+			"windows": {{"Microsoft", "Edge", "User Data"}},
+			"darwin":  {{"Microsoft Edge"}},
+			"": {
+				{"microsoft-edge"},
+				{home, ".var", "app", "com.microsoft.Edge", "config", "microsoft-edge"},
+			},
+		},
+		storage:     "Microsoft Edge Safe Storage",
+		account:     "Microsoft Edge",
+		application: "microsoft-edge",
+		folder:      "Microsoft Edge Keys",
+	},
+	Vivaldi: {
+		name: "vivaldi",
+		dirs: map[string][][]string{
+			"windows": {{"Vivaldi", "User Data"}},
+			"darwin":  {{"Vivaldi"}},
+			"": {
+				{"vivaldi"},
+				{home, ".var", "app", "com.vivaldi.Vivaldi", "config", "vivaldi"},
+			},
+		},
+		storage:     "Vivaldi Safe Storage",
+		account:     "Vivaldi",
+		application: "vivaldi",
+		folder:      "Vivaldi Keys",
+	},
+	Opera: {
+		name: "opera",
+		dirs: map[string][][]string{
+			"windows": {{appData, "Opera Software", "Opera Stable"}},
+			"darwin":  {{"com.operasoftware.Opera"}},
+			"": {
+				{"opera"},
+				{home, "snap", "opera", "current", ".config", "opera"},
+				{home, ".var", "app", "com.opera.Opera", "config", "opera"},
+			},
+		},
+		storage:     "Opera Safe Storage",
+		account:     "Opera",
+		application: "opera",
+		folder:      "Opera Keys",
+	},
+}
+
+// UserDataDirs returns the candidate user data directories for the browser
+// on the current platform, in order of precedence.
+func (b Browser) UserDataDirs() ([]string, error) {
+	info, ok := browserInfos[b]
+	if !ok {
+		return nil, fmt.Errorf("unknown browser %s", b)
+	}
+	paths, ok := info.dirs[runtime.GOOS]
+	if !ok {
+		paths = info.dirs[""]
+	}
+	var dirs []string
+	for _, path := range paths {
+		var base string
+		var err error
+		switch path[0] {
+		case home:
+			base, err = os.UserHomeDir()
+			path = path[1:]
+		case appData:
+			base, err = os.UserConfigDir()
+			path = path[1:]
+		default:
+			if isWindows {
+				base, err = os.UserCacheDir()
+			} else {
+				base, err = os.UserConfigDir()
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, filepath.Join(append([]string{base}, path...)...))
+	}
+	return dirs, nil
+}
+
+// UserDataDir returns the first existing user data directory for the browser.
+func (b Browser) UserDataDir() (string, error) {
+	dirs, err := b.UserDataDirs()
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range dirs {
+		if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("%s: %w (tried %s)", b, ErrUserDataDirNotFound, strings.Join(dirs, ", "))
+}
@@ -0,0 +1,304 @@
+// Package chrome reads cookies from the cookie databases of Chromium based
+// browsers, decrypting cookie values with the key stored in the platform's
+// keyring (the Keychain on macOS, the Secret Service or KWallet on Linux, and
+// DPAPI on Windows).
+//
+// A [Source] is a [ffcookies.CookieSource], reusing the jar, filter and export
+// funcs of ffcookies. Importing the package registers a cookie source for
+// each [Browser] (see [ffcookies.RegisterSource]): "chrome", "chromium",
+// "brave", "edge", "vivaldi" and "opera", selected by prefixing the profile
+// with the source name and a colon (such as "chrome:Profile 1", or "brave:"
+// for the default profile):
+//
+//	import _ "github.com/kenshaw/ffcookies/chrome"
+//
+//	jar, err := ffcookies.ReadJar("chrome:Profile 1", "https://example.com")
+package chrome
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kenshaw/ffcookies"
+	"github.com/kenshaw/ffcookies/internal/sqlite"
+)
+
+func init() {
+	for _, b := range browsers {
+		ffcookies.RegisterSource(b.String(), func(profile string) (ffcookies.CookieSource, error) {
+			return New(WithBrowser(b), WithProfile(profile)), nil
+		})
+	}
+}
+
+// Error is an error.
+type Error string
+
+// Error satisfies the error interface.
+func (err Error) Error() string {
+	return string(err)
+}
+
+// Error values.
+const (
+	// ErrUserDataDirNotFound is the user data directory not found error.
+	ErrUserDataDirNotFound Error = "user data directory not found"
+	// ErrCookieDBNotFound is the cookie database not found error.
+	ErrCookieDBNotFound Error = "cookie database not found"
+	// ErrNoKey is the no key error, returned when the key for encrypted
+	// cookie values could not be retrieved from the platform's keyring.
+	ErrNoKey Error = "no key for encrypted cookie values"
+	// ErrDecrypt is the decrypt error, returned when a cookie value could not
+	// be decrypted (usually because the wrong key was used).
+	ErrDecrypt Error = "unable to decrypt cookie value"
+	// ErrAppBound is the app-bound encryption error, returned for cookie
+	// values encrypted with Chrome's app-bound encryption (v20), which can
+	// only be decrypted by the browser itself.
+	ErrAppBound Error = "app-bound encrypted cookie value"
+	// ErrUnsupported is the unsupported error, returned when the platform's
+	// keyring is not supported.
+	ErrUnsupported Error = "unsupported platform"
+)
+
+// Source is the cookie source for a Chromium based browser's profile.
+type Source struct {
+	browser     Browser
+	userDataDir string
+	profile     string
+	cookieFile  string
+	password    []byte
+	key         []byte
+	logger      *slog.Logger
+
+	mu   sync.Mutex
+	aes  map[string][]byte
+	errs map[string]error
+}
+
+// New creates a cookie source for the Chrome profile, unless otherwise
+// specified by options.
+func New(opts ...Option) *Source {
+	src := &Source{
+		logger: slog.New(slog.DiscardHandler),
+	}
+	for _, o := range opts {
+		o(src)
+	}
+	return src
+}
+
+// Cookies satisfies the [ffcookies.CookieSource] interface. The profile is
+// the profile set with [WithProfile], or the filter's profile, or the
+// "Default" profile.
+//
+// Only the values of the cookies matching the filter are decrypted. Cookies
+// whose values cannot be decrypted (such as values encrypted with Chrome's
+// app-bound encryption, see [ErrAppBound]) are skipped, and logged at the
+// debug level (see [WithLogger]).
+func (src *Source) Cookies(ctx context.Context, filter *ffcookies.Filter) ([]*ffcookies.Entry, error) {
+	file, err := src.CookiePath(filter.Profile)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sqlite.OpenLive(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %w", file, err)
+	}
+	version, err := metaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	t, err := db.Table("cookies")
+	if err != nil {
+		return nil, err
+	}
+	cols := make(map[string]int)
+	for i, name := range t.Columns {
+		cols[name] = i
+	}
+	var res []*ffcookies.Entry
+	err = t.Scan(func(rowid int64, v []any) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		e := &ffcookies.Entry{
+			ID:           rowid,
+			Name:         str(v, cols, "name"),
+			Value:        str(v, cols, "value"),
+			Host:         str(v, cols, "host_key"),
+			Path:         str(v, cols, "path"),
+			Secure:       num(v, cols, "is_secure") != 0,
+			HTTPOnly:     num(v, cols, "is_httponly") != 0,
+			SameSite:     sameSite(num(v, cols, "samesite")),
+			Partitioned:  str(v, cols, "top_frame_site_key") != "",
+			CreationTime: chromeTime(num(v, cols, "creation_utc")),
+			LastAccessed: chromeTime(num(v, cols, "last_access_utc")),
+		}
+		if _, ok := cols["is_persistent"]; !ok || num(v, cols, "is_persistent") != 0 {
+			e.Expires = chromeTime(num(v, cols, "expires_utc"))
+		}
+		// match before decrypting, so that the keyring is only used for
+		// the cookies read
+		if e.Name == "" || !filter.Match(e) {
+			return nil
+		}
+		if enc := blob(v, cols, "encrypted_value"); len(enc) != 0 {
+			buf, err := src.decrypt(ctx, enc)
+			if err != nil {
+				src.logger.Debug("skipping cookie", "name", e.Name, "host", e.Host, "path", e.Path, "error", err)
+				return nil
+			}
+			// databases since version 24 prefix the value with the sha256
+			// of the host
+			if sum := sha256.Sum256([]byte(e.Host)); version >= 24 && len(buf) >= len(sum) && string(buf[:len(sum)]) == string(sum[:]) {
+				buf = buf[len(sum):]
+			}
+			e.Value = string(buf)
+		}
+		res = append(res, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UserDataDir returns the browser's user data directory (the directory
+// containing the Local State file and the profile directories).
+func (src *Source) UserDataDir() (string, error) {
+	if src.userDataDir != "" {
+		return src.userDataDir, nil
+	}
+	return src.browser.UserDataDir()
+}
+
+// CookiePath returns the path of the cookie database for the profile set with
+// [WithProfile], or the provided profile, or the "Default" profile (or the
+// user data directory, when it is the default profile).
+func (src *Source) CookiePath(profile string) (string, error) {
+	if src.cookieFile != "" {
+		return src.cookieFile, nil
+	}
+	if src.profile != "" {
+		profile = src.profile
+	}
+	if profile == "" {
+		profile = "Default"
+	}
+	dir, err := src.UserDataDir()
+	if err != nil {
+		return "", err
+	}
+	dirs := []string{filepath.Join(dir, profile)}
+	if profile == "Default" {
+		// the user data directory is the default profile for some browsers
+		// (such as Opera)
+		dirs = append(dirs, dir)
+	}
+	for _, d := range dirs {
+		for _, name := range [][]string{{"Network", "Cookies"}, {"Cookies"}} {
+			file := filepath.Join(append([]string{d}, name...)...)
+			if _, err := os.Stat(file); err == nil {
+				return file, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w: %s in %s", ErrCookieDBNotFound, profile, dir)
+}
+
+// metaVersion returns the cookie database version from the meta table.
+func metaVersion(db *sqlite.DB) (int, error) {
+	t, err := db.Table("meta")
+	switch {
+	case errors.Is(err, sqlite.ErrNoTable):
+		return 0, nil
+	case err != nil:
+		return 0, err
+	}
+	var version int
+	err = t.Scan(func(_ int64, v []any) error {
+		if len(v) >= 2 && fmt.Sprint(v[0]) == "version" {
+			version, _ = strconv.Atoi(fmt.Sprint(v[1]))
+		}
+		return nil
+	})
+	return version, err
+}
+
+// chromeEpoch is the difference in microseconds between the Windows epoch
+// (1601-01-01), used by Chromium, and the Unix epoch.
+const chromeEpoch = 11644473600000000
+
+// chromeTime converts a Chromium time, or returns the zero time when 0.
+func chromeTime(v int64) time.Time {
+	if v == 0 {
+		return time.Time{}
+	}
+	return time.UnixMicro(v - chromeEpoch)
+}
+
+// sameSite converts a Chromium samesite value.
+func sameSite(v int64) http.SameSite {
+	switch v {
+	case 0:
+		return http.SameSiteNoneMode
+	case 1:
+		return http.SameSiteLaxMode
+	case 2:
+		return http.SameSiteStrictMode
+	}
+	return http.SameSiteDefaultMode
+}
+
+// num returns the integer value of the named column, or 0.
+func num(v []any, cols map[string]int, name string) int64 {
+	if i, ok := cols[name]; ok {
+		switch x := v[i].(type) {
+		case int64:
+			return x
+		case float64:
+			return int64(x)
+		}
+	}
+	return 0
+}
+
+// str returns the string value of the named column, or the empty string.
+func str(v []any, cols map[string]int, name string) string {
+	if i, ok := cols[name]; ok {
+		switch x := v[i].(type) {
+		case string:
+			return x
+		case []byte:
+			return string(x)
+		}
+	}
+	return ""
+}
+
+// blob returns the blob value of the named column, or nil.
+func blob(v []any, cols map[string]int, name string) []byte {
+	if i, ok := cols[name]; ok {
+		switch x := v[i].(type) {
+		case []byte:
+			return x
+		case string:
+			return []byte(x)
+		}
+	}
+	return nil
+}
+
+// isWindows is true on Windows.
+var isWindows = runtime.GOOS == "windows"
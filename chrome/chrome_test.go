@@ -0,0 +1,130 @@
+package chrome
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/kenshaw/ffcookies"
+	"github.com/kenshaw/ffcookies/internal/sqlite"
+)
+
+// cookieValues returns the name=value pairs of the cookies, sorted.
+func cookieValues(entries []*ffcookies.Entry) []string {
+	var v []string
+	for _, e := range entries {
+		v = append(v, e.Name+"="+e.Value)
+	}
+	slices.Sort(v)
+	return v
+}
+
+func TestCookiesCBC(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("v10 values are encrypted with the peanuts password only on linux")
+	}
+	src := New(WithUserDataDir("testdata"))
+	entries, err := src.Cookies(context.Background(), ffcookies.NewFilter("chrome.test"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// the app-bound encrypted value is skipped, and the sha256 of the host is
+	// stripped from the values
+	if v, exp := cookieValues(entries), []string{"plain=text", "pref=dark", "sid=abc123"}; !slices.Equal(v, exp) {
+		t.Errorf("expected %v, got: %v", exp, v)
+	}
+	for _, e := range entries {
+		if e.Name != "sid" {
+			continue
+		}
+		switch {
+		case !e.Secure || !e.HTTPOnly:
+			t.Errorf("expected secure http only cookie")
+		case e.SameSite != http.SameSiteLaxMode:
+			t.Errorf("expected %v, got: %v", http.SameSiteLaxMode, e.SameSite)
+		case e.Expires.Year() != 2100:
+			t.Errorf("expected expiry in 2100, got: %v", e.Expires)
+		}
+	}
+}
+
+func TestCookiesGCM(t *testing.T) {
+	src := New(WithUserDataDir("testdata"), WithProfile("Profile 1"), WithKey(unhex(t, testKey)))
+	entries, err := src.Cookies(context.Background(), ffcookies.NewFilter("chrome.test"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if v, exp := cookieValues(entries), []string{"pref=light", "sid=gcm123"}; !slices.Equal(v, exp) {
+		t.Errorf("expected %v, got: %v", exp, v)
+	}
+	for _, e := range entries {
+		if session := e.Expires.IsZero(); session != (e.Name == "pref") {
+			t.Errorf("expected %s session %t, got: %t", e.Name, e.Name == "pref", session)
+		}
+	}
+}
+
+func TestMetaVersion(t *testing.T) {
+	tests := []struct {
+		file string
+		exp  int
+	}{
+		{"testdata/Default/Cookies", 24},
+		{"testdata/Profile 1/Network/Cookies", 23},
+		{"../testdata/cookies.sqlite", 0},
+	}
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			db, err := sqlite.Open(test.file)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			version, err := metaVersion(db)
+			switch {
+			case err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			case version != test.exp:
+				t.Errorf("expected %d, got: %d", test.exp, version)
+			}
+		})
+	}
+}
+
+func TestCookiePath(t *testing.T) {
+	// the user data directory is the default profile for some browsers
+	opera := t.TempDir()
+	if err := os.WriteFile(filepath.Join(opera, "Cookies"), nil, 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		name    string
+		opts    []Option
+		profile string
+		exp     string
+	}{
+		{"default", []Option{WithUserDataDir("testdata")}, "", "testdata/Default/Cookies"},
+		{"network", []Option{WithUserDataDir("testdata")}, "Profile 1", "testdata/Profile 1/Network/Cookies"},
+		{"option", []Option{WithUserDataDir("testdata"), WithProfile("Default")}, "Profile 1", "testdata/Default/Cookies"},
+		{"cookie file", []Option{WithUserDataDir("testdata"), WithCookieFile("other")}, "Profile 1", "other"},
+		{"user data dir", []Option{WithUserDataDir(opera)}, "", filepath.Join(opera, "Cookies")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file, err := New(test.opts...).CookiePath(test.profile)
+			switch {
+			case err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			case file != filepath.FromSlash(test.exp):
+				t.Errorf("expected %s, got: %s", filepath.FromSlash(test.exp), file)
+			}
+		})
+	}
+	if _, err := New(WithUserDataDir("testdata")).CookiePath("Profile 2"); !errors.Is(err, ErrCookieDBNotFound) {
+		t.Errorf("expected %v, got: %v", ErrCookieDBNotFound, err)
+	}
+}
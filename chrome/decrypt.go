@@ -0,0 +1,174 @@
+package chrome
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// decrypt decrypts an encrypted cookie value.
+func (src *Source) decrypt(ctx context.Context, enc []byte) ([]byte, error) {
+	switch prefix := string(enc[:min(len(enc), 3)]); {
+	case prefix == "v20":
+		return nil, ErrAppBound
+	case (prefix == "v10" || prefix == "v11") && (isWindows || src.key != nil):
+		key, err := src.gcmKey()
+		if err != nil {
+			return nil, err
+		}
+		return decryptGCM(key, enc[3:])
+	case prefix == "v10" || prefix == "v11":
+		key, err := src.cbcKey(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		return decryptCBC(key, enc[3:])
+	case isWindows:
+		// values encrypted directly with DPAPI, before Chrome 80
+		return dpapiDecrypt(enc)
+	}
+	return nil, ErrDecrypt
+}
+
+// cbcKey returns the AES-CBC key for the value version, deriving it from the
+// Safe Storage password.
+func (src *Source) cbcKey(ctx context.Context, version string) ([]byte, error) {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	if key, ok := src.aes[version]; ok {
+		return key, nil
+	}
+	if err, ok := src.errs[version]; ok {
+		return nil, err
+	}
+	password := src.password
+	switch {
+	case version == "v10" && runtime.GOOS != "darwin":
+		// the hardcoded password used on linux without a keyring
+		password = []byte("peanuts")
+	case password != nil:
+	default:
+		var err error
+		if password, err = safeStoragePassword(ctx, browserInfos[src.browser]); err != nil {
+			return nil, src.keyErr(version, fmt.Errorf("%w: %w", ErrNoKey, err))
+		}
+	}
+	iterations := 1
+	if runtime.GOOS == "darwin" {
+		iterations = 1003
+	}
+	key, err := pbkdf2.Key(sha1.New, string(password), []byte("saltysalt"), iterations, 16)
+	if err != nil {
+		return nil, err
+	}
+	if src.aes == nil {
+		src.aes = make(map[string][]byte)
+	}
+	src.aes[version] = key
+	return key, nil
+}
+
+// gcmKey returns the AES-GCM key, decrypting the key in the Local State file
+// with DPAPI.
+func (src *Source) gcmKey() ([]byte, error) {
+	if src.key != nil {
+		return src.key, nil
+	}
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	if key, ok := src.aes["gcm"]; ok {
+		return key, nil
+	}
+	if err, ok := src.errs["gcm"]; ok {
+		return nil, err
+	}
+	dir, err := src.UserDataDir()
+	if err != nil {
+		return nil, src.keyErr("gcm", err)
+	}
+	buf, err := os.ReadFile(filepath.Join(dir, "Local State"))
+	if err != nil {
+		return nil, src.keyErr("gcm", fmt.Errorf("%w: %w", ErrNoKey, err))
+	}
+	var state struct {
+		OSCrypt struct {
+			EncryptedKey string `json:"encrypted_key"`
+		} `json:"os_crypt"`
+	}
+	if err := json.Unmarshal(buf, &state); err != nil {
+		return nil, src.keyErr("gcm", fmt.Errorf("%w: %w", ErrNoKey, err))
+	}
+	enc, err := base64.StdEncoding.DecodeString(state.OSCrypt.EncryptedKey)
+	if err != nil || !bytes.HasPrefix(enc, []byte("DPAPI")) {
+		return nil, src.keyErr("gcm", fmt.Errorf("%w: invalid os_crypt.encrypted_key", ErrNoKey))
+	}
+	key, err := dpapiDecrypt(enc[5:])
+	if err != nil {
+		return nil, src.keyErr("gcm", fmt.Errorf("%w: %w", ErrNoKey, err))
+	}
+	if src.aes == nil {
+		src.aes = make(map[string][]byte)
+	}
+	src.aes["gcm"] = key
+	return key, nil
+}
+
+// keyErr records the error retrieving the key for the value version, so that
+// the keyring is not queried again for each cookie. The source's mutex must
+// be held.
+func (src *Source) keyErr(version string, err error) error {
+	if src.errs == nil {
+		src.errs = make(map[string]error)
+	}
+	src.errs[version] = err
+	return err
+}
+
+// decryptCBC decrypts a value encrypted with AES-CBC, with an iv of 16 spaces
+// and PKCS#7 padding.
+func decryptCBC(key, buf []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) == 0 || len(buf)%aes.BlockSize != 0 {
+		return nil, ErrDecrypt
+	}
+	out := make([]byte, len(buf))
+	cipher.NewCBCDecrypter(block, bytes.Repeat([]byte{' '}, aes.BlockSize)).CryptBlocks(out, buf)
+	n := int(out[len(out)-1])
+	if n == 0 || n > aes.BlockSize || n > len(out) || !bytes.Equal(out[len(out)-n:], bytes.Repeat([]byte{byte(n)}, n)) {
+		return nil, ErrDecrypt
+	}
+	return out[:len(out)-n], nil
+}
+
+// decryptGCM decrypts a value encrypted with AES-GCM, prefixed with its 12
+// byte nonce.
+func decryptGCM(key, buf []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < gcm.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	out, err := gcm.Open(nil, buf[:gcm.NonceSize()], buf[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return out, nil
+}
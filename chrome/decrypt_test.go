@@ -0,0 +1,123 @@
+package chrome
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"runtime"
+	"testing"
+)
+
+// peanutsKey is the AES-CBC key derived from the "peanuts" password used for
+// v10 values on Linux without a keyring.
+const peanutsKey = "fd621fe5a2b402539dfa147ca9272778"
+
+// testKey is the AES-GCM key of the test values.
+const testKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+// unhex decodes the hex string.
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return buf
+}
+
+func TestDecryptCBC(t *testing.T) {
+	tests := []struct {
+		name string
+		enc  string
+		exp  string
+		err  error
+	}{
+		{"value", "763130a3a19df4ac0e38179c41f52115495da1", "value", nil},
+		{"full block", "7631300b9cf0a6eee9cf1aaac601b85ca37cf9848f6a150b749c98d2881c0fa9453f76", "0123456789abcdef", nil},
+		{"bad padding", "7631300b9cf0a6eee9cf1aaac601b85ca3", "", ErrDecrypt},
+		{"partial block", "763130a3a19df4ac0e38179c41f52115495d", "", ErrDecrypt},
+		{"empty", "763130", "", ErrDecrypt},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf, err := decryptCBC(unhex(t, peanutsKey), unhex(t, test.enc)[3:])
+			switch {
+			case test.err != nil && !errors.Is(err, test.err):
+				t.Errorf("expected %v, got: %v", test.err, err)
+			case test.err == nil && err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			case string(buf) != test.exp:
+				t.Errorf("expected %q, got: %q", test.exp, buf)
+			}
+		})
+	}
+}
+
+func TestDecryptGCM(t *testing.T) {
+	const enc = "76313030313233343536373839616241703e06334dff280775ba89413fb3def482d6e741"
+	tests := []struct {
+		name string
+		key  string
+		enc  string
+		exp  string
+		err  error
+	}{
+		{"value", testKey, enc, "value", nil},
+		{"wrong key", peanutsKey + peanutsKey, enc, "", ErrDecrypt},
+		{"tampered", testKey, enc[:len(enc)-2] + "00", "", ErrDecrypt},
+		{"short nonce", testKey, "7631303031323334", "", ErrDecrypt},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf, err := decryptGCM(unhex(t, test.key), unhex(t, test.enc)[3:])
+			switch {
+			case test.err != nil && !errors.Is(err, test.err):
+				t.Errorf("expected %v, got: %v", test.err, err)
+			case test.err == nil && err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			case string(buf) != test.exp:
+				t.Errorf("expected %q, got: %q", test.exp, buf)
+			}
+		})
+	}
+}
+
+func TestCBCKey(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("v10 values use the keychain password on macOS")
+	}
+	key, err := New().cbcKey(context.Background(), "v10")
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case hex.EncodeToString(key) != peanutsKey:
+		t.Errorf("expected %s, got: %x", peanutsKey, key)
+	}
+	key, err = New(WithPassword([]byte("peanuts"))).cbcKey(context.Background(), "v11")
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case hex.EncodeToString(key) != peanutsKey:
+		t.Errorf("expected %s, got: %x", peanutsKey, key)
+	}
+}
+
+func TestDecrypt(t *testing.T) {
+	src := New(WithKey(unhex(t, testKey)))
+	buf, err := src.decrypt(context.Background(), unhex(t, "76313030313233343536373839616241703e06334dff280775ba89413fb3def482d6e741"))
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case string(buf) != "value":
+		t.Errorf("expected %q, got: %q", "value", buf)
+	}
+	if _, err := src.decrypt(context.Background(), []byte("v20value")); !errors.Is(err, ErrAppBound) {
+		t.Errorf("expected %v, got: %v", ErrAppBound, err)
+	}
+	if isWindows {
+		return
+	}
+	if _, err := src.decrypt(context.Background(), []byte("value")); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected %v, got: %v", ErrDecrypt, err)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package chrome
+
+// dpapiDecrypt returns [ErrUnsupported], as DPAPI is only available on
+// Windows.
+func dpapiDecrypt([]byte) ([]byte, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,36 @@
+//go:build windows
+
+package chrome
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	crypt32                = syscall.NewLazyDLL("crypt32.dll")
+	kernel32               = syscall.NewLazyDLL("kernel32.dll")
+	procCryptUnprotectData = crypt32.NewProc("CryptUnprotectData")
+	procLocalFree          = kernel32.NewProc("LocalFree")
+)
+
+// dataBlob is a DATA_BLOB.
+type dataBlob struct {
+	size uint32
+	data *byte
+}
+
+// dpapiDecrypt decrypts the data with DPAPI for the current user.
+func dpapiDecrypt(buf []byte) ([]byte, error) {
+	if len(buf) == 0 {
+		return nil, ErrDecrypt
+	}
+	in := dataBlob{size: uint32(len(buf)), data: &buf[0]}
+	var out dataBlob
+	r, _, err := procCryptUnprotectData.Call(uintptr(unsafe.Pointer(&in)), 0, 0, 0, 0, 0, uintptr(unsafe.Pointer(&out)))
+	if r == 0 {
+		return nil, err
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(out.data)))
+	return append([]byte(nil), unsafe.Slice(out.data, out.size)...), nil
+}
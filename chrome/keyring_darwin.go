@@ -0,0 +1,19 @@
+//go:build darwin
+
+package chrome
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+)
+
+// safeStoragePassword returns the browser's Safe Storage password from the
+// Keychain.
+func safeStoragePassword(ctx context.Context, info browserInfo) ([]byte, error) {
+	buf, err := exec.CommandContext(ctx, "security", "find-generic-password", "-w", "-s", info.storage, "-a", info.account).Output()
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf, "\r\n"), nil
+}
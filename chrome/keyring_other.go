@@ -0,0 +1,11 @@
+//go:build !unix
+
+package chrome
+
+import "context"
+
+// safeStoragePassword returns [ErrUnsupported], as the Safe Storage password
+// is only stored in the keyring on macOS and Linux.
+func safeStoragePassword(context.Context, browserInfo) ([]byte, error) {
+	return nil, ErrUnsupported
+}
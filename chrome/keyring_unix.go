@@ -0,0 +1,28 @@
+//go:build unix && !darwin
+
+package chrome
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+)
+
+// safeStoragePassword returns the browser's Safe Storage password from the
+// Secret Service (such as GNOME Keyring) using secret-tool, or from KWallet
+// using kwallet-query.
+func safeStoragePassword(ctx context.Context, info browserInfo) ([]byte, error) {
+	var errs []error
+	for _, args := range [][]string{
+		{"secret-tool", "lookup", "application", info.application},
+		{"kwallet-query", "--read-password", info.storage, "--folder", info.folder, "kdewallet"},
+	} {
+		buf, err := exec.CommandContext(ctx, args[0], args[1:]...).Output()
+		if buf = bytes.TrimRight(buf, "\r\n"); err == nil && len(buf) != 0 {
+			return buf, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,68 @@
+package chrome
+
+import "log/slog"
+
+// Option is a cookie source option.
+type Option func(*Source)
+
+// WithBrowser is a cookie source option to set the browser. Defaults to
+// [Chrome].
+func WithBrowser(browser Browser) Option {
+	return func(src *Source) {
+		src.browser = browser
+	}
+}
+
+// WithUserDataDir is a cookie source option to set the user data directory,
+// bypassing [Browser.UserDataDir].
+func WithUserDataDir(dir string) Option {
+	return func(src *Source) {
+		src.userDataDir = dir
+	}
+}
+
+// WithProfile is a cookie source option to set the profile directory name
+// (such as "Default" or "Profile 1") in the user data directory.
+func WithProfile(profile string) Option {
+	return func(src *Source) {
+		src.profile = profile
+	}
+}
+
+// WithCookieFile is a cookie source option to set the cookie database path,
+// bypassing the user data directory and profile.
+func WithCookieFile(file string) Option {
+	return func(src *Source) {
+		src.cookieFile = file
+	}
+}
+
+// WithPassword is a cookie source option to set the Safe Storage password
+// used to derive the key of cookie values encrypted with AES-CBC (v10 values
+// on macOS, and v11 values on Linux), instead of retrieving it from the
+// platform's keyring.
+func WithPassword(password []byte) Option {
+	return func(src *Source) {
+		src.password = password
+	}
+}
+
+// WithKey is a cookie source option to set the AES-GCM key of cookie values
+// encrypted on Windows (the DPAPI decrypted os_crypt.encrypted_key in the
+// Local State file), instead of retrieving it with DPAPI. Setting a key
+// allows decrypting a Windows profile's cookies on other platforms.
+func WithKey(key []byte) Option {
+	return func(src *Source) {
+		src.key = key
+	}
+}
+
+// WithLogger is a cookie source option to set a logger for debug messages,
+// such as the cookies skipped because their values could not be decrypted.
+func WithLogger(logger *slog.Logger) Option {
+	return func(src *Source) {
+		if logger != nil {
+			src.logger = logger
+		}
+	}
+}
@@ -0,0 +1,50 @@
+-- default.sql is the test cookie database of the Default profile, at
+-- database version 24. Regenerate Default/Cookies with gen.sh.
+--
+-- Encrypted values are v10 values encrypted with AES-CBC, using the key
+-- derived from the "peanuts" password used on Linux without a keyring, and
+-- prefixed with the sha256 of the host. Times are 2025-01-01 00:00:00 UTC
+-- (13380163200000000) and 2100-01-01 00:00:00 UTC (15746918400000000).
+
+CREATE TABLE meta (
+  key LONGVARCHAR NOT NULL UNIQUE PRIMARY KEY,
+  value LONGVARCHAR
+);
+
+CREATE TABLE cookies (
+  creation_utc INTEGER NOT NULL,
+  host_key TEXT NOT NULL,
+  top_frame_site_key TEXT NOT NULL,
+  name TEXT NOT NULL,
+  value TEXT NOT NULL,
+  encrypted_value BLOB NOT NULL,
+  path TEXT NOT NULL,
+  expires_utc INTEGER NOT NULL,
+  is_secure INTEGER NOT NULL,
+  is_httponly INTEGER NOT NULL,
+  last_access_utc INTEGER NOT NULL,
+  has_expires INTEGER NOT NULL,
+  is_persistent INTEGER NOT NULL,
+  priority INTEGER NOT NULL,
+  samesite INTEGER NOT NULL,
+  source_scheme INTEGER NOT NULL,
+  source_port INTEGER NOT NULL,
+  last_update_utc INTEGER NOT NULL,
+  source_type INTEGER NOT NULL,
+  has_cross_site_ancestor INTEGER NOT NULL,
+  UNIQUE (host_key, top_frame_site_key, name, path, source_scheme, source_port)
+);
+
+INSERT INTO meta (key, value) VALUES ('mmap_status', '-1'), ('version', '24'), ('last_compatible_version', '24');
+
+-- chrome.test: an encrypted domain cookie, an encrypted host-only session
+-- cookie, an unencrypted cookie, and an app-bound encrypted (v20) cookie
+INSERT INTO cookies (creation_utc, host_key, top_frame_site_key, name, value, encrypted_value, path, expires_utc, is_secure, is_httponly, last_access_utc, has_expires, is_persistent, priority, samesite, source_scheme, source_port, last_update_utc, source_type, has_cross_site_ancestor) VALUES
+(13380163200000000, '.chrome.test', '', 'sid', '', X'763130cc77b6d58ff0aee725f263c730be30e9740419f0042703119e24033ab3a54048398020ace7c6c459b47d8165648566f1', '/', 15746918400000000, 1, 1, 13380163200000000, 1, 1, 1, 1, 2, 443, 13380163200000000, 0, 0),
+(13380163200000000, 'chrome.test', '', 'pref', '', X'763130a4637000d43301b27d26ff44db761bbc6cb28923c5260ba8141db2ae138520952193d0e5dff5137b1c8b60ae60bc18ef', '/', 0, 0, 0, 13380163200000000, 0, 0, 1, 0, 2, 443, 13380163200000000, 0, 0),
+(13380163200000000, '.chrome.test', '', 'plain', 'text', X'', '/', 15746918400000000, 0, 0, 13380163200000000, 1, 1, 1, 2, 2, 443, 13380163200000000, 0, 0),
+(13380163200000000, '.chrome.test', '', 'bound', '', X'7632300102030405060708090a0b0c0d0e0f', '/', 15746918400000000, 1, 1, 13380163200000000, 1, 1, 1, 1, 2, 443, 13380163200000000, 0, 0);
+
+-- other.test: an unencrypted cookie for another host
+INSERT INTO cookies (creation_utc, host_key, top_frame_site_key, name, value, encrypted_value, path, expires_utc, is_secure, is_httponly, last_access_utc, has_expires, is_persistent, priority, samesite, source_scheme, source_port, last_update_utc, source_type, has_cross_site_ancestor) VALUES
+(13380163200000000, '.other.test', '', 'other', 'value', X'', '/', 15746918400000000, 0, 0, 13380163200000000, 1, 1, 1, 1, 2, 443, 13380163200000000, 0, 0);
@@ -0,0 +1,13 @@
+#!/bin/bash
+
+# gen.sh regenerates the test cookie databases from default.sql and
+# profile1.sql.
+
+SRC=$(realpath $(cd -P "$(dirname "${BASH_SOURCE[0]}")" && pwd))
+
+set -ex
+
+mkdir -p "$SRC/Default" "$SRC/Profile 1/Network"
+rm -f "$SRC/Default/Cookies" "$SRC/Profile 1/Network/Cookies"
+sqlite3 "$SRC/Default/Cookies" < $SRC/default.sql
+sqlite3 "$SRC/Profile 1/Network/Cookies" < $SRC/profile1.sql
@@ -0,0 +1,44 @@
+-- profile1.sql is the test cookie database of the "Profile 1" profile, at
+-- database version 23, in the profile's Network directory. Regenerate
+-- Profile 1/Network/Cookies with gen.sh.
+--
+-- Encrypted values are v10 values encrypted with AES-GCM, as on Windows, using
+-- the key 000102...1f. Times are 2025-01-01 00:00:00 UTC (13380163200000000)
+-- and 2100-01-01 00:00:00 UTC (15746918400000000).
+
+CREATE TABLE meta (
+  key LONGVARCHAR NOT NULL UNIQUE PRIMARY KEY,
+  value LONGVARCHAR
+);
+
+CREATE TABLE cookies (
+  creation_utc INTEGER NOT NULL,
+  host_key TEXT NOT NULL,
+  top_frame_site_key TEXT NOT NULL,
+  name TEXT NOT NULL,
+  value TEXT NOT NULL,
+  encrypted_value BLOB NOT NULL,
+  path TEXT NOT NULL,
+  expires_utc INTEGER NOT NULL,
+  is_secure INTEGER NOT NULL,
+  is_httponly INTEGER NOT NULL,
+  last_access_utc INTEGER NOT NULL,
+  has_expires INTEGER NOT NULL,
+  is_persistent INTEGER NOT NULL,
+  priority INTEGER NOT NULL,
+  samesite INTEGER NOT NULL,
+  source_scheme INTEGER NOT NULL,
+  source_port INTEGER NOT NULL,
+  last_update_utc INTEGER NOT NULL,
+  source_type INTEGER NOT NULL,
+  has_cross_site_ancestor INTEGER NOT NULL,
+  UNIQUE (host_key, top_frame_site_key, name, path, source_scheme, source_port)
+);
+
+INSERT INTO meta (key, value) VALUES ('mmap_status', '-1'), ('version', '23'), ('last_compatible_version', '23');
+
+-- chrome.test: an encrypted domain cookie and an encrypted host-only session
+-- cookie
+INSERT INTO cookies (creation_utc, host_key, top_frame_site_key, name, value, encrypted_value, path, expires_utc, is_secure, is_httponly, last_access_utc, has_expires, is_persistent, priority, samesite, source_scheme, source_port, last_update_utc, source_type, has_cross_site_ancestor) VALUES
+(13380163200000000, '.chrome.test', '', 'sid', '', X'76313030313233343536373839616250723f426414db931a5769610022e98d6cb072ad369d', '/', 15746918400000000, 1, 1, 13380163200000000, 1, 1, 1, 1, 2, 443, 13380163200000000, 0, 0),
+(13380163200000000, 'chrome.test', '', 'pref', '', X'76313062613938373635343332313033021018b0e72406f132fc9d51452f87a01f828862', '/', 0, 0, 0, 13380163200000000, 0, 0, 1, 0, 2, 443, 13380163200000000, 0, 0);
@@ -0,0 +1,109 @@
+package ffcookies
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Container is a Firefox Multi-Account Container, as stored in a profile's
+// containers.json.
+type Container struct {
+	// ID is the container's user context id, as stored in the userContextId
+	// origin attribute of the container's cookies.
+	ID int `json:"userContextId"`
+	// Name is the container name. For the default containers, the name is
+	// derived from the container's localization id.
+	Name string `json:"name,omitempty"`
+	// L10nID is the localization id of the default containers.
+	L10nID string `json:"l10nId,omitempty"`
+	// Icon is the container icon.
+	Icon string `json:"icon,omitempty"`
+	// Color is the container color.
+	Color string `json:"color,omitempty"`
+	// Public is false for containers used internally by Firefox.
+	Public bool `json:"public"`
+}
+
+// defaultContainerNames are the (English) names of the default containers,
+// which are stored in containers.json without a name.
+var defaultContainerNames = map[string]string{
+	"userContextPersonal.label": "Personal",
+	"userContextWork.label":     "Work",
+	"userContextBanking.label":  "Banking",
+	"userContextShopping.label": "Shopping",
+}
+
+// ReadContainers reads the containers for the provided Firefox profile name,
+// or the default Firefox profile.
+func ReadContainers(profile string, opts ...Option) ([]Container, error) {
+	o := newOptions(opts...)
+	cookiePath, err := o.cookiePath(profile)
+	if err != nil {
+		return nil, err
+	}
+	return readContainers(nil, filepath.Join(filepath.Dir(cookiePath), "containers.json"))
+}
+
+// readContainers reads the containers from the containers.json file in fsys,
+// or on disk when fsys is nil.
+func readContainers(fsys fs.FS, name string) ([]Container, error) {
+	var buf []byte
+	var err error
+	if fsys != nil {
+		buf, err = fs.ReadFile(fsys, name)
+	} else {
+		buf, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var v struct {
+		Identities []Container `json:"identities"`
+	}
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	for i, c := range v.Identities {
+		if c.Name == "" {
+			v.Identities[i].Name = defaultContainerNames[c.L10nID]
+		}
+	}
+	return v.Identities, nil
+}
+
+// resolveContainer resolves the container set by [WithContainer] to its user
+// context id, reading the containers.json alongside the sqlite3 file (in fsys,
+// when not nil) when the container was specified by name.
+func (o *options) resolveContainer(fsys fs.FS, file string) error {
+	if o.container == "" {
+		return nil
+	}
+	if id, err := strconv.Atoi(o.container); err == nil {
+		o.userContextID = id
+		return nil
+	}
+	if file == "" {
+		return fmt.Errorf("%w: %s", ErrUnknownContainer, o.container)
+	}
+	name := filepath.Join(filepath.Dir(dbPath(file)), "containers.json")
+	if fsys != nil {
+		name = path.Join(path.Dir(file), "containers.json")
+	}
+	containers, err := readContainers(fsys, name)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrUnknownContainer, o.container, err)
+	}
+	for _, c := range containers {
+		if strings.EqualFold(c.Name, o.container) {
+			o.userContextID = c.ID
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownContainer, o.container)
+}
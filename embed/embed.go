@@ -0,0 +1,11 @@
+// Package embed registers the modernc.org/sqlite pure Go sqlite3 driver, so
+// that cookie databases are read with a complete sqlite3 implementation
+// without cgo, and without code using ffcookies importing a sqlite3 driver:
+//
+//	import _ "github.com/kenshaw/ffcookies/embed"
+//
+// The package is a separate module, so that modernc.org/sqlite and its
+// dependencies are only required by code importing it.
+package embed
+
+import _ "modernc.org/sqlite"
@@ -0,0 +1,162 @@
+package ffcookies
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/kenshaw/ffcookies/models"
+)
+
+// Entry is a Firefox cookie, including the metadata Firefox stores alongside
+// the cookie.
+type Entry struct {
+	// ID is the cookie's row id in the moz_cookies table.
+	ID int64
+	// Name is the cookie name.
+	Name string
+	// Value is the cookie value.
+	Value string
+	// Host is the cookie host, with a leading dot for domain cookies.
+	Host string
+	// Path is the cookie path.
+	Path string
+	// Expires is the cookie expiry, or the zero time for session cookies.
+	Expires time.Time
+	// Secure is the cookie's Secure attribute.
+	Secure bool
+	// HTTPOnly is the cookie's HttpOnly attribute.
+	HTTPOnly bool
+	// SameSite is the cookie's effective SameSite attribute.
+	SameSite http.SameSite
+	// Partitioned is the cookie's Partitioned (CHIPS) attribute. See
+	// [Entry.Origin] for the cookie's partition key.
+	Partitioned bool
+	// RawSameSite is the SameSite value sent by the server, before Firefox
+	// applied its defaults.
+	RawSameSite models.SameSite
+	// SchemeMap is the set of schemes the cookie was set or sent over.
+	SchemeMap models.SchemeMap
+	// InBrowserElement is true when the cookie was set in a browser element
+	// (such as a mozbrowser iframe).
+	InBrowserElement bool
+	// CreationTime is the time the cookie was created.
+	CreationTime time.Time
+	// LastAccessed is the time the cookie was last accessed.
+	LastAccessed time.Time
+	// OriginAttributes are the cookie's raw origin attributes. See
+	// [Entry.Origin].
+	OriginAttributes string
+	// Extra are the values of the database columns not mapped to the other
+	// fields, keyed by column name. See [models.Schema.Extra].
+	Extra map[string]any
+
+	cookie *http.Cookie
+}
+
+// newEntry creates an entry for the row and its converted cookie.
+func newEntry(c *models.Cookie, cookie *http.Cookie) *Entry {
+	return &Entry{
+		ID:               c.ID,
+		Name:             cookie.Name,
+		Value:            cookie.Value,
+		Host:             c.Host,
+		Path:             cookie.Path,
+		Expires:          cookie.Expires,
+		Secure:           cookie.Secure,
+		HTTPOnly:         cookie.HttpOnly,
+		SameSite:         cookie.SameSite,
+		Partitioned:      cookie.Partitioned,
+		RawSameSite:      c.RawSameSite,
+		SchemeMap:        c.SchemeMap,
+		InBrowserElement: c.InBrowserElement,
+		CreationTime:     c.Created(),
+		LastAccessed:     c.Accessed(),
+		OriginAttributes: c.OriginAttributes,
+		Extra:            c.Extra,
+		cookie:           cookie,
+	}
+}
+
+// Cookie returns the entry as a http.Cookie. A new cookie is returned on
+// each call.
+func (e *Entry) Cookie() *http.Cookie {
+	if e.cookie == nil {
+		// an entry from a cookie source
+		return &http.Cookie{
+			Name:        e.Name,
+			Value:       e.Value,
+			Path:        e.Path,
+			Domain:      e.Host,
+			Expires:     e.Expires,
+			Secure:      e.Secure,
+			HttpOnly:    e.HTTPOnly,
+			SameSite:    e.SameSite,
+			Partitioned: e.Partitioned,
+		}
+	}
+	cookie := *e.cookie
+	cookie.Unparsed = append([]string(nil), e.cookie.Unparsed...)
+	return &cookie
+}
+
+// Session returns true when the entry is a session cookie.
+func (e *Entry) Session() bool {
+	return e.Expires.IsZero()
+}
+
+// Origin returns the parsed origin attributes of the entry.
+func (e *Entry) Origin() (models.OriginAttributes, error) {
+	return models.ParseOriginAttributes(e.OriginAttributes)
+}
+
+// ReadEntries reads the cookies using the provided options (see
+// [ReadCookies]), returning entries with the cookies' metadata.
+func ReadEntries(ctx context.Context, opts ...Option) ([]*Entry, error) {
+	o := newOptions(opts...)
+	if err := o.selectSource(); err != nil {
+		return nil, err
+	}
+	if o.source != nil {
+		return o.source.Cookies(ctx, o.filter())
+	}
+	return o.readEntries(ctx)
+}
+
+// readEntries reads the entries for the profile and host.
+func (o *options) readEntries(ctx context.Context) ([]*Entry, error) {
+	file, err := o.profileFile(o.profile)
+	if err != nil {
+		return nil, err
+	}
+	// open database
+	src, closeSrc, err := o.open(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSrc()
+	return o.entries(ctx, src, file, o.host)
+}
+
+// entries reads the entries for the host from the opened sqlite3 file.
+func (o *options) entries(ctx context.Context, src source, file, host string) ([]*Entry, error) {
+	res, err := o.rows(ctx, src, file, host)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*Entry, 0, len(res))
+	cookies := make([]*http.Cookie, 0, len(res))
+	for _, c := range res {
+		if cookie := o.convert(c); cookie != nil {
+			entries, cookies = append(entries, newEntry(c, cookie)), append(cookies, cookie)
+		}
+	}
+	if err := o.sameSiteNone.apply(cookies); err != nil {
+		return nil, err
+	}
+	// the policy may have changed the cookies
+	for _, e := range entries {
+		e.Secure = e.cookie.Secure
+	}
+	return entries, nil
+}
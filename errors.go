@@ -0,0 +1,217 @@
+package ffcookies
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Error is an error.
+type Error string
+
+// Error satisfies the error interface.
+func (err Error) Error() string {
+	return string(err)
+}
+
+// Error values.
+const (
+	// ErrInsecureSameSiteNone is the insecure SameSite=None error.
+	ErrInsecureSameSiteNone Error = "SameSite=None cookie without Secure"
+	// ErrCorruptDatabase is the corrupt database error, returned when the
+	// file is not a valid sqlite3 database (Firefox cookie databases are not
+	// encrypted, so this usually means the wrong file was used). Errors for
+	// corrupt databases are returned as a [CorruptError], which matches
+	// ErrCorruptDatabase with [errors.Is].
+	ErrCorruptDatabase Error = "corrupt database"
+	// ErrUnknownContainer is the unknown container error.
+	ErrUnknownContainer Error = "unknown container"
+	// ErrOriginCollision is the origin collision error, returned when
+	// building a cookie jar from cookies that differ only by origin attributes
+	// with [WithOriginCollisionError].
+	ErrOriginCollision Error = "cookies differ only by origin attributes"
+	// ErrNoDriver is the no sqlite3 driver error, returned when a sqlite3
+	// driver is required but none has been imported (see [WithDriver]).
+	ErrNoDriver Error = "no sqlite driver: code using ffcookies must import a sqlite driver"
+	// ErrProfileDirNotFound is the profile directory not found error, returned
+	// when none of the base profile directories exist (see [ProfileDirs]), or
+	// when the directory set with [WithProfileDir] does not exist.
+	ErrProfileDirNotFound Error = "firefox profile directory not found"
+	// ErrProfileNotFound is the profile not found error, returned when the
+	// named profile, or a default profile, does not exist.
+	ErrProfileNotFound Error = "firefox profile not found"
+	// ErrCookieDBNotFound is the cookie database not found error, returned
+	// when the profile does not have a cookie database.
+	ErrCookieDBNotFound Error = "cookie database not found"
+	// ErrDatabaseLocked is the database locked error. Errors for busy or
+	// locked databases are returned as a [LockedError], which matches
+	// ErrDatabaseLocked with [errors.Is].
+	ErrDatabaseLocked Error = "database locked"
+	// ErrUnsupportedArchive is the unsupported archive error, returned when
+	// a profile backup is not a zip or tar archive. See [ReadArchive].
+	ErrUnsupportedArchive Error = "unsupported archive"
+	// ErrUnknownSource is the unknown cookie source error. See
+	// [RegisterSource].
+	ErrUnknownSource Error = "unknown cookie source"
+)
+
+// LockedError is the error returned when a cookie database is busy or
+// locked, such as by a running Firefox. See [LockStrategy].
+type LockedError struct {
+	// File is the sqlite3 file.
+	File string
+	// InUse is true when the database's profile is in use by a running
+	// Firefox. See [ProfileInUse].
+	InUse bool
+	// Err is the underlying error.
+	Err error
+}
+
+// Error satisfies the error interface.
+func (err *LockedError) Error() string {
+	s := "database"
+	if err.File != "" {
+		s += " " + err.File
+	}
+	s += " is locked"
+	if err.InUse {
+		s += " (Firefox is running: close Firefox, or read a copy of the database)"
+	}
+	return s + ": " + err.Err.Error()
+}
+
+// Unwrap satisfies the unwrap interface.
+func (err *LockedError) Unwrap() error {
+	return err.Err
+}
+
+// Is satisfies the is interface, matching [ErrDatabaseLocked].
+func (err *LockedError) Is(target error) bool {
+	return target == ErrDatabaseLocked
+}
+
+// CorruptError is the error returned when a file is not a valid sqlite3
+// database, or the database is corrupt. See [ErrCorruptDatabase].
+type CorruptError struct {
+	// File is the sqlite3 file.
+	File string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error satisfies the error interface.
+func (err *CorruptError) Error() string {
+	s := string(ErrCorruptDatabase)
+	if err.File != "" {
+		s += " " + err.File
+	}
+	return s + ": " + err.Err.Error()
+}
+
+// Unwrap satisfies the unwrap interface.
+func (err *CorruptError) Unwrap() error {
+	return err.Err
+}
+
+// Is satisfies the is interface, matching [ErrCorruptDatabase].
+func (err *CorruptError) Is(target error) bool {
+	return target == ErrCorruptDatabase
+}
+
+// dbError wraps database errors for the sqlite3 file, identifying errors
+// caused by a corrupt (or non-sqlite3) database file, and busy or locked
+// databases.
+func dbError(file string, err error) error {
+	var lockedErr *LockedError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &lockedErr):
+		return err
+	case isBusy(err):
+		return &LockedError{
+			File:  dbPath(file),
+			InUse: fileInUse(file),
+			Err:   err,
+		}
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{
+		"file is not a database",
+		"file is encrypted or is not a database",
+		"database disk image is malformed",
+	} {
+		if strings.Contains(msg, s) {
+			return &CorruptError{
+				File: dbPath(file),
+				Err:  err,
+			}
+		}
+	}
+	return err
+}
+
+// dbExists returns [ErrCookieDBNotFound] when the sqlite3 file does not
+// exist.
+func dbExists(file string) error {
+	if _, err := os.Stat(dbPath(file)); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrCookieDBNotFound, dbPath(file))
+	}
+	return nil
+}
+
+// dbPath returns the path of the sqlite3 file, removing the file: prefix, any
+// authority and open parameters, and unescaping the path. See [fileURI].
+func dbPath(file string) string {
+	if !strings.HasPrefix(file, "file:") {
+		return file
+	}
+	file, _, _ = strings.Cut(strings.TrimPrefix(file, "file:"), "?")
+	file, _, _ = strings.Cut(file, "#")
+	if strings.HasPrefix(file, "//") {
+		// authority (empty or localhost)
+		if i := strings.IndexByte(file[2:], '/'); i != -1 {
+			file = file[2+i:]
+		}
+	}
+	if s, err := url.PathUnescape(file); err == nil {
+		file = s
+	}
+	if runtime.GOOS == "windows" && len(file) > 2 && file[0] == '/' && file[2] == ':' {
+		// drive letter
+		file = file[1:]
+	}
+	return filepath.FromSlash(file)
+}
+
+// fileURI returns the sqlite3 file: URI for the path with the open params.
+// Backslashes are converted to slashes, Windows drive letters and UNC paths
+// are made absolute, and characters that sqlite3 treats specially in URIs
+// (such as %, ? and #) are escaped.
+func fileURI(name, params string) string {
+	vol := filepath.VolumeName(name)
+	name = filepath.ToSlash(name)
+	switch {
+	case strings.HasPrefix(vol, `\\`) || strings.HasPrefix(vol, "//"):
+		// UNC path, use an empty authority
+		name = "//" + name
+	case vol != "":
+		// drive letter
+		name = "/" + name
+	}
+	var b strings.Builder
+	for i := range len(name) {
+		switch c := name[i]; {
+		case c == '%' || c == '?' || c == '#' || c <= ' ' || c >= 0x7f:
+			fmt.Fprintf(&b, "%%%02X", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "file:" + b.String() + params
+}
@@ -0,0 +1,46 @@
+package ffcookies
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCorruptDatabase(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"text", []byte("# Netscape HTTP Cookie File\n")},
+		{"random", bytes.Repeat([]byte{0x8f, 0x1e, 0x42, 0xa7}, 1024)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "cookies.sqlite")
+			if err := os.WriteFile(file, test.data, 0o644); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			_, err := ReadFile(file, "")
+			if !errors.Is(err, ErrCorruptDatabase) {
+				t.Fatalf("expected corrupt database error, got: %v", err)
+			}
+			var corruptErr *CorruptError
+			if !errors.As(err, &corruptErr) || corruptErr.File != file {
+				t.Errorf("expected corrupt database error for %s, got: %v", file, err)
+			}
+			if _, err := ReadBytes(context.Background(), test.data, ""); !errors.Is(err, ErrCorruptDatabase) {
+				t.Errorf("expected corrupt database error reading bytes, got: %v", err)
+			}
+			r, err := ValidateDatabase(context.Background(), file)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if r.OK() || !errors.Is(r.Err(), ErrCorruptDatabase) {
+				t.Errorf("expected corrupt database report, got: %+v", r)
+			}
+		})
+	}
+}
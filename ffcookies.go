@@ -1,5 +1,11 @@
 // Package ffookies provides a quick way to read cookies from a firefox browser
 // profile.
+//
+// Cookie databases are opened with an imported sqlite3 driver (see
+// [RegisterDriverName]). Importing github.com/kenshaw/ffcookies/embed (a
+// separate module) registers the modernc.org/sqlite driver. When no sqlite3
+// driver has been imported, cookies are read with a minimal, built-in
+// read-only sqlite3 reader.
 package ffcookies
 
 //go:generate ./gen.sh
@@ -7,14 +13,17 @@ package ffcookies
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/kenshaw/ffcookies/models"
 	"golang.org/x/net/publicsuffix"
@@ -23,6 +32,18 @@ import (
 // DefaultOpenParams are the default open parameters to use.
 var DefaultOpenParams = "?nolock=1&immutable=1&mode=ro"
 
+// Environment variables used when resolving profiles. Disable with
+// [WithEnv].
+const (
+	// EnvProfileDir is the environment variable for the base profile
+	// directory, used unless a base profile directory was set with
+	// [WithProfileDir].
+	EnvProfileDir = "FFCOOKIES_PROFILE_DIR"
+	// EnvProfile is the environment variable for the profile name, used when
+	// no profile name was provided.
+	EnvProfile = "FFCOOKIES_PROFILE"
+)
+
 /*
 
 sq:/home/ken/cookies.sqlite=> \d moz_cookies
@@ -51,111 +72,294 @@ Indexes:
 */
 
 // ReadFileContext reads the cookies from the provided sqlite3 file on disk.
-func ReadFileContext(ctx context.Context, file, host string) ([]*http.Cookie, error) {
-	// check sqlite driver
-	driver := driverName()
-	if driver == "" {
-		return nil, errors.New("code using ffookies must import a sqlite driver!")
-	}
+func ReadFileContext(ctx context.Context, file, host string, opts ...Option) ([]*http.Cookie, error) {
+	o := newOptions(opts...)
 	// open database
-	db, err := sql.Open(driver, file)
+	src, closeSrc, err := o.open(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSrc()
+	return o.read(ctx, src, file, host)
+}
+
+// read reads the cookies for the host from the opened sqlite3 file.
+func (o *options) read(ctx context.Context, src source, file, host string) ([]*http.Cookie, error) {
+	res, err := o.rows(ctx, src, file, host)
 	if err != nil {
 		return nil, err
 	}
-	defer db.Close()
-	// query func and params
-	f := models.Cookies
-	if host != "" {
-		f, host = models.CookiesLikeHost, "%"+strings.TrimPrefix(host, "%")
+	return o.convertAll(res)
+}
+
+// convertAll converts the rows to cookies.
+func (o *options) convertAll(res []*models.Cookie) ([]*http.Cookie, error) {
+	cookies := make([]*http.Cookie, 0, len(res))
+	for _, c := range res {
+		if cookie := o.convert(c); cookie != nil {
+			cookies = append(cookies, cookie)
+		}
+	}
+	if err := o.sameSiteNone.apply(cookies); err != nil {
+		return nil, err
 	}
+	return cookies, nil
+}
+
+// rows reads the rows for the host from the opened sqlite3 file, filtering
+// and deduplicating the rows as specified by the options.
+func (o *options) rows(ctx context.Context, src source, file, host string) ([]*models.Cookie, error) {
+	// build query
+	q := o.query(host)
 	// exec and convert
-	res, err := f(ctx, db, host)
+	var res []*models.Cookie
+	err := o.retry(ctx, func() error {
+		var err error
+		res, err = collect(src.cookies(ctx, q))
+		return err
+	})
 	if err != nil {
+		return nil, dbError(file, err)
+	}
+	o.logger.Debug("read cookies", "where", q.where(), "rows", len(res))
+	if o.emptyNameErr {
+		if err := checkEmptyNames(res); err != nil {
+			return nil, err
+		}
+	}
+	if o.partitionSite != nil {
+		res = filterPartitioned(res, o.partitionSite, o.includePartitioned)
+	}
+	if o.partitionKey != nil {
+		res = filterPartitionKey(res, o.partitionKey)
+	}
+	if o.dedupeOrigins {
+		var collisions []*models.Cookie
+		switch res, collisions = dedupeOrigins(res, o.preferOrigin); {
+		case len(collisions) != 0 && o.originErr:
+			return nil, fmt.Errorf("%w: %s", ErrOriginCollision, strings.Join(originKeys(collisions), ", "))
+		case len(collisions) != 0:
+			o.logger.Warn("resolved cookies differing only by origin attributes", "cookies", originKeys(collisions))
+		}
+	}
+	return res, nil
+}
+
+// convert converts the cookie, adding metadata and decoding its value as
+// specified by the options. Returns nil when the cookie has an empty name.
+func (o *options) convert(c *models.Cookie) *http.Cookie {
+	cookie := models.ConvertOneDefault(c, o.defaultSameSite)
+	if cookie == nil {
+		return nil
+	}
+	if o.metadata {
+		addMetadata(cookie, c)
+	}
+	if o.decodeValues {
+		if v, err := url.PathUnescape(cookie.Value); err == nil {
+			cookie.Value = v
+		}
+	}
+	return cookie
+}
+
+// ReadDBContext reads the cookies for the host from an already opened
+// sqlite3 database, such as a database managed by a connection pool or an
+// in-memory database. The database is not closed.
+func ReadDBContext(ctx context.Context, db *sql.DB, host string, opts ...Option) ([]*http.Cookie, error) {
+	o := newOptions(opts...)
+	if err := o.resolveContainer(nil, ""); err != nil {
 		return nil, err
 	}
-	return models.Convert(res), nil
+	return o.read(ctx, dbSource{db}, "", host)
+}
+
+// ReadDB reads the cookies for the host from an already opened sqlite3
+// database.
+func ReadDB(db *sql.DB, host string, opts ...Option) ([]*http.Cookie, error) {
+	return ReadDBContext(context.Background(), db, host, opts...)
 }
 
 // ReadFile reads the cookies from the provided sqlite3 file on disk.
-func ReadFile(file, host string) ([]*http.Cookie, error) {
-	return ReadFileContext(context.Background(), file, host)
+func ReadFile(file, host string, opts ...Option) ([]*http.Cookie, error) {
+	return ReadFileContext(context.Background(), file, host, opts...)
 }
 
-// ReadContext reads the cookies for the provided Firefox profile name, or the
-// default Firefox profile.
-func ReadContext(ctx context.Context, profile, host string) ([]*http.Cookie, error) {
-	profileDir := profileDir()
-	if profileDir == "" {
-		return nil, errors.New("cannot determine the firefox profile directory")
+// ReadCookies reads the cookies using the provided options. Reads all
+// cookies in the default Firefox profile, unless otherwise specified by
+// options such as [WithProfile], [WithHost], [WithProfileDir],
+// [WithProfilePath] and [WithCookieFile].
+func ReadCookies(ctx context.Context, opts ...Option) ([]*http.Cookie, error) {
+	return newOptions(opts...).readCookies(ctx)
+}
+
+// readCookies reads the cookies from the selected cookie source, or the
+// profile's cookie database.
+func (o *options) readCookies(ctx context.Context) ([]*http.Cookie, error) {
+	if err := o.selectSource(); err != nil {
+		return nil, err
+	}
+	if o.source != nil {
+		return o.sourceCookies(ctx)
+	}
+	file, err := o.profileFile(o.profile)
+	if err != nil {
+		return nil, err
 	}
-	cookiePath, err := cookiePath(profileDir, profile)
+	// open database
+	src, closeSrc, err := o.open(ctx, file)
 	if err != nil {
 		return nil, err
 	}
-	return ReadFileContext(ctx, "file:"+cookiePath+DefaultOpenParams, host)
+	defer closeSrc()
+	return o.read(ctx, src, file, o.host)
+}
+
+// ReadContext reads the cookies for the provided Firefox profile name, or the
+// default Firefox profile. The profile can also be the absolute path of a
+// profile directory or a cookie database. See [WithProfilePath] and
+// [WithCookieFile] for reading from a specific location.
+func ReadContext(ctx context.Context, profile, host string, opts ...Option) ([]*http.Cookie, error) {
+	return ReadCookies(ctx, append([]Option{WithProfile(profile), WithHost(host)}, opts...)...)
 }
 
 // Read reads the cookies for the provided Firefox profile name.
-func Read(profile, host string) ([]*http.Cookie, error) {
-	return ReadContext(context.Background(), profile, host)
+func Read(profile, host string, opts ...Option) ([]*http.Cookie, error) {
+	return ReadContext(context.Background(), profile, host, opts...)
 }
 
 // Jar builds a cookie jar for the url from provided cookies.
+//
+// Cookies with a domain are set in the jar for their own domain, and
+// host-only cookies (those with a domain lacking a leading dot) are not sent
+// to subdomains. Cookies without a domain are set for the url, and are
+// skipped when the url is nil.
+//
+// Cookies sent by an [http.Client] using the jar have their values sanitized
+// by net/http. Use [AddCookies] when values must be sent exactly as stored.
 func Jar(u *url.URL, cookies ...*http.Cookie) (http.CookieJar, error) {
+	return JarWithPublicSuffixList(u, publicsuffix.List, cookies...)
+}
+
+// JarWithPublicSuffixList builds a cookie jar for the url from provided
+// cookies, using the public suffix list. A nil list allows domain cookies to
+// be set for public suffixes, such as home.arpa on intranets. See [Jar].
+func JarWithPublicSuffixList(u *url.URL, list cookiejar.PublicSuffixList, cookies ...*http.Cookie) (http.CookieJar, error) {
 	// build jar
 	jar, err := cookiejar.New(&cookiejar.Options{
-		PublicSuffixList: publicsuffix.List,
+		PublicSuffixList: list,
 	})
 	if err != nil {
 		return nil, err
 	}
-	jar.SetCookies(u, cookies)
+	for _, cookie := range cookies {
+		v, c := jarCookie(u, cookie)
+		if v == nil {
+			continue
+		}
+		jar.SetCookies(v, []*http.Cookie{c})
+		// the jar only strips the brackets of IPv6 hosts with a port, so set
+		// the cookie for both forms of the host
+		if h := v.Hostname(); strings.Contains(h, ":") && v.Port() == "" {
+			w := *v
+			w.Host = net.JoinHostPort(h, "443")
+			jar.SetCookies(&w, []*http.Cookie{c})
+		}
+	}
 	return jar, nil
 }
 
-// ReadJarContext reads the cookies from the provided sqlite3 file for the provided
-// url into a cookie jar usable with http.Client.
-func ReadJarContext(ctx context.Context, profile, urlstr string) (http.CookieJar, error) {
-	// read cookies
-	u, err := url.Parse(urlstr)
-	if err != nil {
-		return nil, err
+// jarCookie returns the url and cookie to use when setting the cookie in a
+// jar. Host-only cookies are set without a domain, so that the jar does not
+// send them to subdomains. Cookies without a domain are set for u, if not
+// nil.
+func jarCookie(u *url.URL, cookie *http.Cookie) (*url.URL, *http.Cookie) {
+	if cookie.Domain == "" {
+		return u, cookie
 	}
-	switch strings.ToLower(u.Scheme) {
-	case "http", "https", "ws", "wss":
-	default:
-		return nil, fmt.Errorf("invalid url scheme %q", u.Scheme)
+	host := strings.TrimPrefix(cookie.Domain, ".")
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
 	}
-	cookies, err := ReadContext(ctx, profile, u.Host)
-	if err != nil {
-		return nil, err
+	v := &url.URL{
+		Scheme: "https",
+		Host:   host,
+		Path:   cookie.Path,
 	}
-	return Jar(u, cookies...)
+	if strings.HasPrefix(cookie.Domain, ".") {
+		return v, cookie
+	}
+	c := *cookie
+	c.Domain = ""
+	return v, &c
+}
+
+// ReadJarContext reads the cookies from the provided sqlite3 file for the provided
+// url into a cookie jar usable with http.Client. The url may be a bare host,
+// such as example.com, in which case https is assumed.
+//
+// The cookies for the url's host and its parent domains (up to the
+// registrable domain) are read. See [WithDomainMatch].
+func ReadJarContext(ctx context.Context, profile, urlstr string, opts ...Option) (http.CookieJar, error) {
+	return ReadJarFilteredContext(ctx, profile, urlstr, nil, opts...)
 }
 
 // ReadJar reads the cookies from the provided sqlite3 file for the provided
 // url into a cookie jar usable with http.Client.
-func ReadJar(profile, urlstr string) (http.CookieJar, error) {
-	return ReadJarContext(context.Background(), profile, urlstr)
+func ReadJar(profile, urlstr string, opts ...Option) (http.CookieJar, error) {
+	return ReadJarContext(context.Background(), profile, urlstr, opts...)
+}
+
+// ReadJarExactContext reads the cookies for the provided url's exact host into
+// a cookie jar usable with http.Client. Unlike [ReadJarContext], which reads
+// the cookies for the host and its parent domains, only the cookies set for
+// the url's host itself are read. See [WithExactHost].
+func ReadJarExactContext(ctx context.Context, profile, urlstr string, opts ...Option) (http.CookieJar, error) {
+	return ReadJarContext(ctx, profile, urlstr, append([]Option{WithExactHost()}, opts...)...)
+}
+
+// ReadJarExact reads the cookies for the provided url's exact host into a
+// cookie jar usable with http.Client.
+func ReadJarExact(profile, urlstr string, opts ...Option) (http.CookieJar, error) {
+	return ReadJarExactContext(context.Background(), profile, urlstr, opts...)
+}
+
+// ReadJarAllContext reads all cookies for the provided Firefox profile name
+// (or the default Firefox profile) into a single cookie jar usable with
+// http.Client for requests to any host. Each cookie is set in the jar for its
+// own domain and path.
+func ReadJarAllContext(ctx context.Context, profile string, opts ...Option) (http.CookieJar, error) {
+	opts = append([]Option{jarOption(false)}, opts...)
+	cookies, err := ReadContext(ctx, profile, "", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return JarWithPublicSuffixList(nil, newOptions(opts...).publicSuffixList, cookies...)
+}
+
+// ReadJarAll reads all cookies for the provided Firefox profile name (or the
+// default Firefox profile) into a single cookie jar usable with http.Client
+// for requests to any host.
+func ReadJarAll(profile string, opts ...Option) (http.CookieJar, error) {
+	return ReadJarAllContext(context.Background(), profile, opts...)
 }
 
 // ReadJarFilteredContext reads the cookies from the provided sqlite3 file for
 // the provided url into a cookie jar (usable with http.Client) consisting of
-// cookies passed through filter func f.
-func ReadJarFilteredContext(ctx context.Context, profile, urlstr string, f func(*http.Cookie) bool) (http.CookieJar, error) {
+// cookies passed through filter func f. A nil f does not filter any cookies.
+//
+// Cookies that differ only by origin attributes (such as the same cookie in
+// multiple containers) are resolved as described in
+// [WithPreferOriginAttributes]. Partitioned cookies are excluded, unless
+// [WithIncludePartitioned] or [WithPartitionKey] is passed.
+func ReadJarFilteredContext(ctx context.Context, profile, urlstr string, f func(*http.Cookie) bool, opts ...Option) (http.CookieJar, error) {
 	// read cookies
-	u, err := url.Parse(urlstr)
+	u, o, cookies, err := readJar(ctx, profile, urlstr, opts...)
 	if err != nil {
 		return nil, err
 	}
-	switch strings.ToLower(u.Scheme) {
-	case "http", "https", "ws", "wss":
-	default:
-		return nil, fmt.Errorf("invalid url scheme %q", u.Scheme)
-	}
-	cookies, err := ReadContext(ctx, profile, u.Host)
-	if err != nil {
-		return nil, err
+	if f == nil {
+		return JarWithPublicSuffixList(u, o.publicSuffixList, cookies...)
 	}
 	// filter
 	var c []*http.Cookie
@@ -164,48 +368,164 @@ func ReadJarFilteredContext(ctx context.Context, profile, urlstr string, f func(
 			c = append(c, cookie)
 		}
 	}
-	return Jar(u, c...)
+	return JarWithPublicSuffixList(u, o.publicSuffixList, c...)
 }
 
 // ReadJarFiltered reads the cookies from the provided sqlite3 file for the
 // provided url into a cookie jar (usable with http.Client) consisting of
 // cookies passed through filter func f.
-func ReadJarFiltered(profile, urlstr string, f func(*http.Cookie) bool) (http.CookieJar, error) {
-	return ReadJarFilteredContext(context.Background(), profile, urlstr, f)
+func ReadJarFiltered(profile, urlstr string, f func(*http.Cookie) bool, opts ...Option) (http.CookieJar, error) {
+	return ReadJarFilteredContext(context.Background(), profile, urlstr, f, opts...)
 }
 
-// driverName returns the first sqlite3 driver name it encounters.
-func driverName() string {
-	for _, n := range sql.Drivers() {
-		switch n {
-		case "sqlite3", "sqlite":
-			return n
+// checkEmptyNames returns an error listing the cookies with empty names.
+func checkEmptyNames(res []*models.Cookie) error {
+	var rows []string
+	for _, c := range res {
+		if c.Name == "" {
+			rows = append(rows, c.Host+c.Path)
 		}
 	}
-	return ""
+	if len(rows) != 0 {
+		return fmt.Errorf("%d cookies with empty names: %s", len(rows), strings.Join(rows, ", "))
+	}
+	return nil
+}
+
+// readJar reads the cookies for the url to be added to a jar.
+func readJar(ctx context.Context, profile, urlstr string, opts ...Option) (*url.URL, *options, []*http.Cookie, error) {
+	u, err := jarURL(urlstr)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	opts = append([]Option{jarOption(true)}, opts...)
+	cookies, err := ReadContext(ctx, profile, urlHost(u), opts...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return u, newOptions(opts...), cookies, nil
+}
+
+// jarOption returns a read option for reading the cookies added to a jar.
+// Cookies differing only by origin attributes are resolved to a single cookie
+// (see [WithPreferOriginAttributes]), and partitioned cookies are excluded
+// (see [WithIncludePartitioned]). When domainMatch is true, the cookies for
+// the host and its parent domains are read.
+func jarOption(domainMatch bool) Option {
+	return func(o *options) {
+		o.dedupeOrigins = true
+		o.domainMatch = domainMatch
+		o.excludePartitioned = true
+	}
 }
 
-// profileDir returns the base profile directory for firefox.
-func profileDir() string {
-	if dir, err := os.UserHomeDir(); err == nil {
-		return filepath.Join(dir, ".mozilla", "firefox")
+// jarURL parses and checks the url used to build a cookie jar. A bare host
+// (such as example.com) is treated as a https url.
+func jarURL(urlstr string) (*url.URL, error) {
+	if !strings.Contains(urlstr, "://") {
+		urlstr = "https://" + urlstr
+	}
+	u, err := url.Parse(urlstr)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "ws", "wss":
+	default:
+		return nil, fmt.Errorf("invalid url scheme %q", u.Scheme)
+	}
+	return u, nil
+}
+
+// urlHost returns the normalized host of the url, as stored by Firefox: the
+// lower case ASCII (punycode) host name, without a port, the brackets of an
+// IPv6 literal, or a trailing dot.
+func urlHost(u *url.URL) string {
+	return asciiHost(strings.TrimSuffix(strings.ToLower(u.Hostname()), "."))
+}
+
+// driverNames are the registered sqlite3 driver names, in order of
+// preference.
+var driverNames = struct {
+	sync.Mutex
+	names []string
+}{
+	names: []string{"sqlite3", "sqlite"},
+}
+
+// RegisterDriverName registers a database/sql driver name (such as
+// "sqlite3" for github.com/mattn/go-sqlite3, or "sqlite" for
+// modernc.org/sqlite) as a sqlite3 driver. Drivers are used in the reverse
+// order of registration, so that the most recently registered driver is
+// preferred when multiple sqlite3 drivers have been imported. The "sqlite3"
+// and "sqlite" driver names are registered by default. See [WithDriver] for
+// selecting the driver for a single read.
+func RegisterDriverName(name string) {
+	driverNames.Lock()
+	defer driverNames.Unlock()
+	driverNames.names = append([]string{name}, slices.DeleteFunc(driverNames.names, func(n string) bool {
+		return n == name
+	})...)
+}
+
+// driverName returns the most preferred registered sqlite3 driver name.
+func driverName() string {
+	driverNames.Lock()
+	defer driverNames.Unlock()
+	drivers := sql.Drivers()
+	for _, name := range driverNames.names {
+		if slices.Contains(drivers, name) {
+			return name
+		}
 	}
 	return ""
 }
 
-// cookiePath determines the cookie file path.
-func cookiePath(dir, profile string) (string, error) {
-	if profile == "" {
-		entries, err := os.ReadDir(dir)
+// ProfileDir returns the base profile directory for firefox (the directory
+// containing profiles.ini) from the platform's candidate directories. When
+// multiple candidate directories exist, the one with the most recently
+// modified cookie database is used. See [ProfileDirs] and
+// [Browser.ProfileDir].
+func ProfileDir() (string, error) {
+	return Firefox.ProfileDir()
+}
+
+// newestDir returns the base profile directory with the most recently
+// modified cookie database, such as when both a classic and a snap install of
+// Firefox have been used. Returns the first directory when none of the
+// directories have a cookie database.
+func newestDir(dirs []string) string {
+	dir := dirs[0]
+	var newest time.Time
+	for _, d := range dirs {
+		profiles, err := osFS.discoverProfiles(d)
 		if err != nil {
-			return "", err
+			continue
+		}
+		var paths []string
+		for _, p := range profiles {
+			paths = append(paths, p.Path)
 		}
-		for _, entry := range entries {
-			if name := entry.Name(); entry.IsDir() && strings.HasSuffix(name, ".default-release") {
-				dir = filepath.Join(dir, name)
-				break
-			}
+		path, _ := osFS.newestCookies(paths)
+		if path == "" {
+			continue
+		}
+		if fi, err := os.Stat(filepath.Join(path, "cookies.sqlite")); err == nil && fi.ModTime().After(newest) {
+			dir, newest = d, fi.ModTime()
 		}
 	}
-	return filepath.Join(dir, profile, "cookies.sqlite"), nil
+	return dir
+}
+
+// ProfileDirs returns the candidate base profile directories for firefox on
+// the current platform, in order of precedence. On Windows, this is
+// %APPDATA%\Mozilla\Firefox, and on macOS, this is ~/Library/Application
+// Support/Firefox. Otherwise, alternate casings of ~/.mozilla/firefox, the
+// snap package's ~/snap/firefox/common/.mozilla/firefox, and the flatpak's
+// ~/.var/app/org.mozilla.firefox/.mozilla/firefox are checked.
+//
+// See [ProfileDir] for the directory that is used, and [Browser.ProfileDirs]
+// for the candidate directories of other browsers.
+func ProfileDirs() ([]string, error) {
+	return Firefox.ProfileDirs()
 }
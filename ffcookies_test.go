@@ -0,0 +1,331 @@
+package ffcookies
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// testDB is the test cookie database. See testdata/gen.sh.
+const testDB = "testdata/cookies.sqlite"
+
+// testNow is the time the test cookie database is relative to.
+var testNow = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// clockAt returns a read option setting the clock to t.
+func clockAt(t time.Time) Option {
+	return WithClock(func() time.Time {
+		return t
+	})
+}
+
+// cookieNames returns the sorted names of the cookies.
+func cookieNames(cookies []*http.Cookie) []string {
+	var names []string
+	for _, cookie := range cookies {
+		names = append(names, cookie.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestReadFileEmptyNames(t *testing.T) {
+	cookies, err := ReadFile(testDB, "emptyname.test")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if names, exp := cookieNames(cookies), []string{"ok"}; !slices.Equal(names, exp) {
+		t.Errorf("expected %v, got: %v", exp, names)
+	}
+	_, err = ReadFile(testDB, "emptyname.test", WithEmptyNameError())
+	switch {
+	case err == nil:
+		t.Fatalf("expected error")
+	case !strings.Contains(err.Error(), "2 cookies with empty names"):
+		t.Errorf("expected error listing 2 cookies, got: %v", err)
+	}
+}
+
+func TestJarHostOnly(t *testing.T) {
+	cookies, err := ReadFile(testDB, "hostonly.test")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, cookie := range cookies {
+		if hostOnly, exp := !strings.HasPrefix(cookie.Domain, "."), cookie.Name == "host"; hostOnly != exp {
+			t.Errorf("expected %s host-only %t, got: %t", cookie.Name, exp, hostOnly)
+		}
+	}
+	jar, err := Jar(nil, cookies...)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		urlstr string
+		exp    []string
+	}{
+		{"https://hostonly.test", []string{"domain", "host"}},
+		{"https://sub.hostonly.test", []string{"domain"}},
+	}
+	for _, test := range tests {
+		t.Run(test.urlstr, func(t *testing.T) {
+			if names := jarNames(t, jar, test.urlstr); !slices.Equal(names, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, names)
+			}
+		})
+	}
+}
+
+// jarNames returns the sorted names of the cookies in the jar for the url.
+func jarNames(t *testing.T, jar http.CookieJar, urlstr string) []string {
+	t.Helper()
+	return cookieNames(jarCookies(t, jar, urlstr))
+}
+
+// jarCookies returns the cookies in the jar for the url.
+func jarCookies(t *testing.T, jar http.CookieJar, urlstr string) []*http.Cookie {
+	t.Helper()
+	u, err := url.Parse(urlstr)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return jar.Cookies(u)
+}
+
+func TestReadJarFiltered(t *testing.T) {
+	f := func(cookie *http.Cookie) bool {
+		return cookie.Name == "domain"
+	}
+	readers := []struct {
+		name string
+		read func(context.Context) (http.CookieJar, error)
+	}{
+		{"context", func(ctx context.Context) (http.CookieJar, error) {
+			return ReadJarFilteredContext(ctx, "", "https://hostonly.test", f, WithCookieFile(testDB))
+		}},
+		{"no context", func(context.Context) (http.CookieJar, error) {
+			return ReadJarFiltered("", "https://hostonly.test", f, WithCookieFile(testDB))
+		}},
+	}
+	for _, test := range readers {
+		t.Run(test.name, func(t *testing.T) {
+			jar, err := test.read(context.Background())
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if names, exp := jarNames(t, jar, "https://hostonly.test"), []string{"domain"}; !slices.Equal(names, exp) {
+				t.Errorf("expected %v, got: %v", exp, names)
+			}
+		})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := readers[0].read(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled error, got: %v", err)
+	}
+}
+
+func TestReadJarBareHost(t *testing.T) {
+	jar, err := ReadJar("", "hostonly.test", WithCookieFile(testDB))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if names, exp := jarNames(t, jar, "https://hostonly.test"), []string{"domain", "host"}; !slices.Equal(names, exp) {
+		t.Errorf("expected %v, got: %v", exp, names)
+	}
+}
+
+func TestJarURL(t *testing.T) {
+	tests := []struct {
+		urlstr string
+		exp    string
+	}{
+		{"example.com", "https://example.com"},
+		{"example.com:8443/a", "https://example.com:8443/a"},
+		{"http://example.com", "http://example.com"},
+		{"WSS://example.com", "wss://example.com"},
+		{"ftp://example.com", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.urlstr, func(t *testing.T) {
+			u, err := jarURL(test.urlstr)
+			switch {
+			case test.exp == "" && err == nil:
+				t.Fatalf("expected error, got: %s", u)
+			case test.exp == "":
+				return
+			case err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if s := u.String(); s != test.exp {
+				t.Errorf("expected %s, got: %s", test.exp, s)
+			}
+		})
+	}
+}
+
+func TestReadJarPublicSuffixList(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		exp  []string
+	}{
+		{"default", nil, nil},
+		{"disabled", []Option{WithPublicSuffixList(nil)}, []string{"sso"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jar, err := ReadJar("", "https://nas.home.arpa", append([]Option{WithCookieFile(testDB)}, test.opts...)...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if names := jarNames(t, jar, "https://nas.home.arpa/"); !slices.Equal(names, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, names)
+			}
+		})
+	}
+}
+
+func TestJarWithPublicSuffixList(t *testing.T) {
+	tests := []struct {
+		domain string
+		list   cookiejar.PublicSuffixList
+		urlstr string
+		exp    []string
+	}{
+		{".home.arpa", publicsuffix.List, "https://nas.home.arpa", nil},
+		{".home.arpa", nil, "https://nas.home.arpa", []string{"sso"}},
+		{"intranet", nil, "https://intranet", []string{"sso"}},
+		{".intranet", nil, "https://intranet", []string{"sso"}},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			cookie := &http.Cookie{Name: "sso", Value: "1", Domain: test.domain, Path: "/"}
+			jar, err := JarWithPublicSuffixList(nil, test.list, cookie)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if names := jarNames(t, jar, test.urlstr); !slices.Equal(names, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, names)
+			}
+		})
+	}
+}
+
+func TestReadJarAll(t *testing.T) {
+	jar, err := ReadJarAll("", WithCookieFile(testDB))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		urlstr string
+		exp    []string
+	}{
+		{"https://hostonly.test", []string{"domain", "host"}},
+		{"https://www.stats.test", []string{"a", "b", "d"}},
+		{"https://stats.test", []string{"a", "b"}},
+		{"https://www.export.test/a", []string{"httponly", "live", "session"}},
+	}
+	for _, test := range tests {
+		t.Run(test.urlstr, func(t *testing.T) {
+			if names := jarNames(t, jar, test.urlstr); !slices.Equal(names, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, names)
+			}
+		})
+	}
+}
+
+func TestReadFileNullColumns(t *testing.T) {
+	cookies, err := ReadFile(testDB, "null.test")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cookies) != 1 {
+		t.Fatalf("expected 1 cookie, got: %d", len(cookies))
+	}
+	if cookies[0].Value != "" {
+		t.Errorf("expected empty value, got: %q", cookies[0].Value)
+	}
+	if cookies[0].Path != "" {
+		t.Errorf("expected empty path, got: %q", cookies[0].Path)
+	}
+}
+
+func TestReadJarExact(t *testing.T) {
+	tests := []struct {
+		name string
+		read func(string, string, ...Option) (http.CookieJar, error)
+		exp  []string
+	}{
+		{"suffix", ReadJar, []string{"parent", "www"}},
+		{"exact", ReadJarExact, []string{"www"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jar, err := test.read("", "https://www.exact.test", WithCookieFile(testDB))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if names := jarNames(t, jar, "https://www.exact.test"); !slices.Equal(names, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, names)
+			}
+		})
+	}
+}
+
+func TestURLHost(t *testing.T) {
+	tests := []struct {
+		urlstr string
+		exp    string
+	}{
+		{"https://example.com", "example.com"},
+		{"https://example.com:8443/a", "example.com"},
+		{"https://EXAMPLE.com.", "example.com"},
+		{"https://127.0.0.1:8443", "127.0.0.1"},
+		{"https://[::1]:8443", "::1"},
+		{"https://[2001:DB8::1]", "2001:db8::1"},
+		{"https://bücher.example", "xn--bcher-kva.example"},
+	}
+	for _, test := range tests {
+		t.Run(test.urlstr, func(t *testing.T) {
+			u, err := url.Parse(test.urlstr)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if host := urlHost(u); host != test.exp {
+				t.Errorf("expected %q, got: %q", test.exp, host)
+			}
+		})
+	}
+}
+
+func TestReadJarNormalizedHost(t *testing.T) {
+	tests := []struct {
+		urlstr string
+		exp    []string
+	}{
+		{"https://hostonly.test:8443/", []string{"domain", "host"}},
+		{"https://HostOnly.Test/", []string{"domain", "host"}},
+		{"https://[::1]:8443/", []string{"v6"}},
+	}
+	for _, test := range tests {
+		t.Run(test.urlstr, func(t *testing.T) {
+			jar, err := ReadJar("", test.urlstr, WithCookieFile(testDB))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if names := jarNames(t, jar, test.urlstr); !slices.Equal(names, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package ffcookies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ReadFS reads the cookies for the host from the sqlite3 file at name in the
+// file system, such as an [embed.FS], a zip file's [archive/zip.Reader], or a
+// [testing/fstest.MapFS]. Pass [WithLiveDatabase] to also read the
+// database's write-ahead log (name with a -wal suffix).
+//
+// When no sqlite3 driver has been imported, the database is read in memory.
+// Otherwise, the database is copied to a temporary directory and opened with
+// the sqlite3 driver.
+func ReadFS(ctx context.Context, fsys fs.FS, name, host string, opts ...Option) ([]*http.Cookie, error) {
+	o := newOptions(opts...)
+	src, closeSrc, err := o.openFS(ctx, fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSrc()
+	return o.read(ctx, src, name, host)
+}
+
+// ListProfilesFS lists the Firefox profiles in the Firefox profile directory
+// dir in the file system, such as a profile backup or a remote host's file
+// system. Absolute profile paths in profiles.ini and installs.ini are
+// relative to the root of the file system. See [ListProfiles].
+func ListProfilesFS(fsys fs.FS, dir string) ([]Profile, error) {
+	return profileFS{fsys: fsys}.discoverProfiles(dir)
+}
+
+// CookiePathFS returns the path of the cookie database for the profile in the
+// Firefox profile directory dir in the file system, or the default profile
+// when profile is empty. See [ListProfilesFS].
+func CookiePathFS(fsys fs.FS, dir, profile string) (string, error) {
+	pfs := profileFS{fsys: fsys}
+	p, err := pfs.profilePath(dir, profile)
+	if err != nil {
+		return "", err
+	}
+	return pfs.join(p, "cookies.sqlite"), nil
+}
+
+// openFS opens the sqlite3 file at name in the file system as a cookie
+// source, returning the source and a func closing it.
+func (o *options) openFS(ctx context.Context, fsys fs.FS, name string) (source, func() error, error) {
+	buf, err := fs.ReadFile(fsys, name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, nil, fmt.Errorf("%w: %s", ErrCookieDBNotFound, name)
+	case err != nil:
+		return nil, nil, err
+	}
+	var wal []byte
+	if o.live {
+		switch wal, err = fs.ReadFile(fsys, name+"-wal"); {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, nil, err
+		}
+	}
+	if err := o.resolveContainer(fsys, name); err != nil {
+		return nil, nil, err
+	}
+	return o.openBuf(ctx, path.Base(name), buf, wal)
+}
+
+// openBuf opens the contents of a sqlite3 file (and its write-ahead log, when
+// not empty) as a cookie source, returning the source and a func closing it.
+// The name is used for the copy written to the temporary directory when
+// reading with a sqlite3 driver.
+func (o *options) openBuf(ctx context.Context, name string, buf, wal []byte) (source, func() error, error) {
+	if o.driver == "" && driverName() == "" {
+		src, err := o.openMem(name, buf, wal)
+		if err != nil {
+			return nil, nil, err
+		}
+		return src, func() error { return nil }, nil
+	}
+	dir, cleanup, err := o.mkTemp()
+	if err != nil {
+		return nil, nil, err
+	}
+	dest := filepath.Join(dir, name)
+	// remove any stale write-ahead log (when the temporary directory is reused)
+	_ = os.Remove(dest + "-wal")
+	_ = os.Remove(dest + "-shm")
+	if err := os.WriteFile(dest, buf, 0o600); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	if len(wal) != 0 {
+		if err := os.WriteFile(dest+"-wal", wal, 0o600); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+	}
+	o.logger.Debug("copied database", "name", name, "copy", dest)
+	db, err := o.openDB(ctx, fileURI(dest, ""))
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return dbSource{db}, func() error {
+		defer cleanup()
+		return db.Close()
+	}, nil
+}
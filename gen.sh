@@ -8,7 +8,7 @@ set -x
 
 TYPE_COMMENT='{{ . }} is a browser cookie.'
 FUNC_COMMENT='{{ . }} retrieves cookies.'
-FIELDS='Expiry int64,Host string,Name string,Value string,Path string,IsSecure bool,IsHTTPOnly bool'
+FIELDS='Expiry int64,Host string,Name string,Value string,Path string,IsSecure bool,IsHTTPOnly bool,SameSite SameSite,RawSameSite SameSite,OriginAttributes string,CreationTime int64,LastAccessed int64,ID int64,SchemeMap SchemeMap,InBrowserElement bool,IsPartitioned bool'
 dbtpl query "$SQDB" \
   --type Cookie \
   --type-comment="$TYPE_COMMENT" \
@@ -23,13 +23,22 @@ dbtpl query "$SQDB" \
 << 'ENDSQL'
 /* %%host string,interpolate%% */
 SELECT
-  expiry,
-  host,
-  name,
-  value,
-  path,
-  isSecure,
-  isHttpOnly
+  COALESCE(expiry, 0),
+  COALESCE(host, ''),
+  COALESCE(name, ''),
+  COALESCE(value, ''),
+  COALESCE(path, ''),
+  COALESCE(isSecure, 0),
+  COALESCE(isHttpOnly, 0),
+  COALESCE(sameSite, 0),
+  COALESCE(rawSameSite, 0),
+  COALESCE(originAttributes, ''),
+  COALESCE(creationTime, 0),
+  COALESCE(lastAccessed, 0),
+  id,
+  COALESCE(schemeMap, 0),
+  COALESCE(inBrowserElement, 0),
+  COALESCE(isPartitionedAttributeSet, 0)
 FROM moz_cookies
 ENDSQL
 
@@ -46,13 +55,26 @@ dbtpl query "$SQDB" \
   --single=models.go \
 << 'ENDSQL'
 SELECT
-  expiry,
-  host,
-  name,
-  value,
-  path,
-  isSecure,
-  isHttpOnly
+  COALESCE(expiry, 0),
+  COALESCE(host, ''),
+  COALESCE(name, ''),
+  COALESCE(value, ''),
+  COALESCE(path, ''),
+  COALESCE(isSecure, 0),
+  COALESCE(isHttpOnly, 0),
+  COALESCE(sameSite, 0),
+  COALESCE(rawSameSite, 0),
+  COALESCE(originAttributes, ''),
+  COALESCE(creationTime, 0),
+  COALESCE(lastAccessed, 0),
+  id,
+  COALESCE(schemeMap, 0),
+  COALESCE(inBrowserElement, 0),
+  COALESCE(isPartitionedAttributeSet, 0)
 FROM moz_cookies
 WHERE host LIKE %%host string%%
 ENDSQL
+
+# add the values of columns not mapped to fields (see Schema.Extra)
+sed -i 's|^\(\tIsPartitioned .*\)$|\1\n\tExtra map[string]any `json:"extra,omitempty"` // columns not mapped to fields|' $SRC/models/models.go
+gofmt -w $SRC/models/models.go
@@ -2,4 +2,10 @@ module github.com/kenshaw/ffcookies
 
 go 1.24.3
 
-require golang.org/x/net v0.40.0
+require (
+	golang.org/x/crypto v0.38.0
+	golang.org/x/net v0.40.0
+	golang.org/x/sync v0.14.0
+)
+
+require golang.org/x/text v0.25.0 // indirect
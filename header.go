@@ -0,0 +1,47 @@
+package ffcookies
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CookieHeader builds a Cookie header value from the cookies, preserving each
+// cookie's name and value byte-for-byte, as Firefox does.
+//
+// net/http sanitizes cookie values when adding them to a request: bytes not
+// permitted by RFC 6265 are dropped, and values containing a space or a comma
+// are wrapped in double quotes. Firefox sends stored values as-is, so sites
+// with unusual session tokens may reject the sanitized form. Values of cookies
+// marked as quoted are wrapped in double quotes, matching how they are stored.
+func CookieHeader(cookies ...*http.Cookie) string {
+	var sb strings.Builder
+	for _, cookie := range cookies {
+		if sb.Len() != 0 {
+			sb.WriteString("; ")
+		}
+		sb.WriteString(cookie.Name)
+		sb.WriteByte('=')
+		if cookie.Quoted {
+			sb.WriteByte('"')
+		}
+		sb.WriteString(cookie.Value)
+		if cookie.Quoted {
+			sb.WriteByte('"')
+		}
+	}
+	return sb.String()
+}
+
+// AddCookies adds the cookies to the request's Cookie header without the
+// value sanitization performed by [http.Request.AddCookie]. See
+// [CookieHeader].
+func AddCookies(req *http.Request, cookies ...*http.Cookie) {
+	if len(cookies) == 0 {
+		return
+	}
+	s := CookieHeader(cookies...)
+	if c := req.Header.Get("Cookie"); c != "" {
+		s = c + "; " + s
+	}
+	req.Header.Set("Cookie", s)
+}
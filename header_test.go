@@ -0,0 +1,52 @@
+package ffcookies
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kenshaw/ffcookies/models"
+)
+
+func TestCookieHeader(t *testing.T) {
+	tests := []struct {
+		cookies []*http.Cookie
+		exp     string
+	}{
+		{nil, ""},
+		{[]*http.Cookie{{Name: "a", Value: "1"}}, "a=1"},
+		{[]*http.Cookie{{Name: "a", Value: "x y,z"}}, "a=x y,z"},
+		{[]*http.Cookie{{Name: "a", Value: "x y,z", Quoted: true}}, `a="x y,z"`},
+		{[]*http.Cookie{{Name: "a", Value: "1"}, {Name: "b", Value: "x y,z"}}, "a=1; b=x y,z"},
+	}
+	for _, test := range tests {
+		t.Run(test.exp, func(t *testing.T) {
+			if s := CookieHeader(test.cookies...); s != test.exp {
+				t.Errorf("expected %q, got: %q", test.exp, s)
+			}
+		})
+	}
+}
+
+func TestAddCookies(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://example.com", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	req.Header.Set("Cookie", "a=1")
+	cookie := models.ConvertOne(&models.Cookie{
+		Name:  "b",
+		Value: "x y,z",
+		Host:  ".example.com",
+		Path:  "/",
+	})
+	AddCookies(req, cookie)
+	if s, exp := req.Header.Get("Cookie"), "a=1; b=x y,z"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+	// net/http quotes the value
+	req.Header.Del("Cookie")
+	req.AddCookie(cookie)
+	if s, exp := req.Header.Get("Cookie"), `b="x y,z"`; s != exp {
+		t.Errorf("expected net/http to quote the value as %q, got: %q", exp, s)
+	}
+}
@@ -0,0 +1,32 @@
+package ffcookies
+
+import (
+	"strings"
+)
+
+// iniSection is a section of an ini file.
+type iniSection struct {
+	Name string
+	Keys map[string]string
+}
+
+// parseIni parses the sections of an ini file. Keys outside of a section are
+// ignored.
+func parseIni(s string) []iniSection {
+	var sections []iniSection
+	for _, line := range strings.Split(strings.TrimPrefix(s, "\ufeff"), "\n") {
+		switch line = strings.TrimSpace(line); {
+		case line == "", line[0] == ';', line[0] == '#':
+		case line[0] == '[' && line[len(line)-1] == ']':
+			sections = append(sections, iniSection{
+				Name: strings.TrimSpace(line[1 : len(line)-1]),
+				Keys: make(map[string]string),
+			})
+		case len(sections) != 0:
+			if k, v, ok := strings.Cut(line, "="); ok {
+				sections[len(sections)-1].Keys[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+	}
+	return sections
+}
@@ -0,0 +1,104 @@
+// Package mozlz4 decodes Mozilla's mozlz4 files (such as Firefox's
+// sessionstore.jsonlz4), which are a single LZ4 block prefixed by a magic
+// number and the decompressed size.
+package mozlz4
+
+import (
+	"encoding/binary"
+)
+
+// Error is an error.
+type Error string
+
+// Error satisfies the error interface.
+func (err Error) Error() string {
+	return string(err)
+}
+
+// Error values.
+const (
+	// ErrInvalidMagic is the invalid magic error.
+	ErrInvalidMagic Error = "invalid mozlz4 magic"
+	// ErrCorrupt is the corrupt data error.
+	ErrCorrupt Error = "corrupt mozlz4 data"
+)
+
+// Magic is the magic number of mozlz4 files.
+const Magic = "mozLz40\x00"
+
+// Decode decodes the contents of a mozlz4 file.
+func Decode(buf []byte) ([]byte, error) {
+	if len(buf) < len(Magic)+4 || string(buf[:len(Magic)]) != Magic {
+		return nil, ErrInvalidMagic
+	}
+	size := binary.LittleEndian.Uint32(buf[len(Magic):])
+	return decodeBlock(buf[len(Magic)+4:], int(size))
+}
+
+// maxRatio is the maximum compression ratio of a LZ4 block, used to reject
+// corrupt decompressed sizes before allocating the decompressed data.
+const maxRatio = 255
+
+// decodeBlock decodes a LZ4 block with the decompressed size.
+func decodeBlock(src []byte, size int) ([]byte, error) {
+	if size < 0 || size > maxRatio*len(src) {
+		return nil, ErrCorrupt
+	}
+	dst := make([]byte, 0, size)
+	for i := 0; i < len(src); {
+		token := src[i]
+		i++
+		// literals
+		n, ok := length(src, &i, int(token>>4))
+		if !ok || n > len(src)-i || n > size-len(dst) {
+			return nil, ErrCorrupt
+		}
+		dst = append(dst, src[i:i+n]...)
+		i += n
+		if i == len(src) {
+			// the last sequence has only literals
+			break
+		}
+		// match
+		if i+2 > len(src) {
+			return nil, ErrCorrupt
+		}
+		offset := int(binary.LittleEndian.Uint16(src[i:]))
+		i += 2
+		if offset == 0 || offset > len(dst) {
+			return nil, ErrCorrupt
+		}
+		n, ok = length(src, &i, int(token&0xf))
+		if n += 4; !ok || n > size-len(dst) {
+			return nil, ErrCorrupt
+		}
+		// matches may overlap the bytes being written
+		for start := len(dst) - offset; n > 0; n-- {
+			dst = append(dst, dst[start])
+			start++
+		}
+	}
+	if len(dst) != size {
+		return nil, ErrCorrupt
+	}
+	return dst, nil
+}
+
+// length reads the extended length of a literal or match length n starting
+// at src[*i], advancing i.
+func length(src []byte, i *int, n int) (int, bool) {
+	if n != 0xf {
+		return n, true
+	}
+	for {
+		if *i >= len(src) {
+			return 0, false
+		}
+		b := src[*i]
+		*i++
+		n += int(b)
+		if b != 0xff {
+			return n, true
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package mozlz4
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// encode encodes the contents of a mozlz4 file, compressing buf as a LZ4
+// block with greedy matches of at least 4 bytes.
+func encode(buf []byte) []byte {
+	dst := binary.LittleEndian.AppendUint32([]byte(Magic), uint32(len(buf)))
+	// appendLength appends the extended length n
+	appendLength := func(n int) {
+		for ; n >= 0xff; n -= 0xff {
+			dst = append(dst, 0xff)
+		}
+		dst = append(dst, byte(n))
+	}
+	// appendSequence appends the literals and a match of length n at offset
+	appendSequence := func(lit []byte, offset, n int) {
+		token := byte(min(len(lit), 0xf)) << 4
+		if offset != 0 {
+			token |= byte(min(n-4, 0xf))
+		}
+		dst = append(dst, token)
+		if len(lit) >= 0xf {
+			appendLength(len(lit) - 0xf)
+		}
+		dst = append(dst, lit...)
+		if offset == 0 {
+			return
+		}
+		dst = binary.LittleEndian.AppendUint16(dst, uint16(offset))
+		if n-4 >= 0xf {
+			appendLength(n - 4 - 0xf)
+		}
+	}
+	start := 0
+	// the last 5 bytes are always literals
+	for i := 0; i+12 <= len(buf); {
+		var offset, n int
+		for j := max(0, i-0xffff); j < i; j++ {
+			m := 0
+			for i+m < len(buf)-5 && buf[j+m] == buf[i+m] {
+				m++
+			}
+			if m >= 4 && m > n {
+				offset, n = i-j, m
+			}
+		}
+		if n == 0 {
+			i++
+			continue
+		}
+		appendSequence(buf[start:i], offset, n)
+		i += n
+		start = i
+	}
+	appendSequence(buf[start:], 0, 0)
+	return dst
+}
+
+func TestDecode(t *testing.T) {
+	tests := []string{
+		"",
+		"a",
+		"hello, world",
+		strings.Repeat("a", 1000),
+		strings.Repeat("abcdefghijklmnopqrstuvwxyz", 100),
+		`{"cookies":[{"host":".example.com","name":"a","value":"1","path":"/"},{"host":".example.com","name":"b","value":"2","path":"/"}]}`,
+	}
+	for _, test := range tests {
+		t.Run(test[:min(len(test), 16)], func(t *testing.T) {
+			enc := encode([]byte(test))
+			if len(test) > 100 && len(enc) >= len(Magic)+4+len(test) {
+				t.Errorf("expected compressed data, got: %d bytes", len(enc))
+			}
+			buf, err := Decode(enc)
+			switch {
+			case err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			case string(buf) != test:
+				t.Errorf("expected %q, got: %q", test, buf)
+			}
+		})
+	}
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	enc := encode([]byte(strings.Repeat("abcdefghijklmnopqrstuvwxyz", 100)))
+	tests := []struct {
+		name string
+		f    func([]byte) []byte
+		err  error
+	}{
+		{"magic", func(buf []byte) []byte { buf[0] = 'x'; return buf }, ErrInvalidMagic},
+		{"short", func(buf []byte) []byte { return buf[:10] }, ErrInvalidMagic},
+		{"truncated", func(buf []byte) []byte { return buf[:len(buf)-10] }, ErrCorrupt},
+		{"larger size", func(buf []byte) []byte {
+			binary.LittleEndian.PutUint32(buf[len(Magic):], 2601)
+			return buf
+		}, ErrCorrupt},
+		{"smaller size", func(buf []byte) []byte {
+			binary.LittleEndian.PutUint32(buf[len(Magic):], 2599)
+			return buf
+		}, ErrCorrupt},
+		{"huge size", func(buf []byte) []byte {
+			binary.LittleEndian.PutUint32(buf[len(Magic):], 0xffffffff)
+			return buf
+		}, ErrCorrupt},
+		{"offset", func(buf []byte) []byte {
+			// the first match's offset (following the token, the
+			// extended literal length and 26 literals) is past the start
+			// of the data
+			binary.LittleEndian.PutUint16(buf[len(Magic)+4+2+26:], 100)
+			return buf
+		}, ErrCorrupt},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Decode(test.f(bytes.Clone(enc))); !errors.Is(err, test.err) {
+				t.Errorf("expected %v, got: %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestDecodeMaxSize(t *testing.T) {
+	enc := encode([]byte("abc"))
+	binary.LittleEndian.PutUint32(enc[len(Magic):], 0xffffffff)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := Decode(enc)
+	runtime.ReadMemStats(&after)
+	switch {
+	case !errors.Is(err, ErrCorrupt):
+		t.Errorf("expected %v, got: %v", ErrCorrupt, err)
+	case after.TotalAlloc-before.TotalAlloc > 1<<20:
+		t.Errorf("expected the size to be rejected before allocating, got: %d bytes allocated", after.TotalAlloc-before.TotalAlloc)
+	}
+}
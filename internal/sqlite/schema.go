@@ -0,0 +1,158 @@
+package sqlite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTable parses the CREATE TABLE statement of a table.
+func parseTable(db *DB, name string, root int, sql string) (*Table, error) {
+	start, end := strings.IndexByte(sql, '('), strings.LastIndexByte(sql, ')')
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("%w: invalid table %s", ErrMalformed, name)
+	}
+	if strings.Contains(strings.ToUpper(sql[end:]), "WITHOUT ROWID") {
+		return nil, fmt.Errorf("table %s: WITHOUT ROWID tables are not supported", name)
+	}
+	t := &Table{
+		db:    db,
+		root:  root,
+		rowid: -1,
+		Name:  name,
+	}
+	for _, def := range splitDefs(sql[start+1 : end]) {
+		tokens := tokenize(def)
+		if len(tokens) == 0 {
+			continue
+		}
+		switch strings.ToUpper(tokens[0]) {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+			continue
+		}
+		var typ string
+		if len(tokens) > 1 {
+			typ = strings.ToUpper(tokens[1])
+		}
+		upper := strings.ToUpper(def)
+		if typ == "INTEGER" && strings.Contains(upper, "PRIMARY KEY") && !strings.Contains(upper, "DESC") {
+			t.rowid = len(t.Columns)
+		}
+		t.Columns = append(t.Columns, unquote(tokens[0]))
+		t.defaults = append(t.defaults, defaultValue(tokens))
+	}
+	return t, nil
+}
+
+// splitDefs splits the column definitions and table constraints of a CREATE
+// TABLE statement.
+func splitDefs(s string) []string {
+	var defs []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '[':
+			quote = ']'
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			defs = append(defs, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(defs, strings.TrimSpace(s[start:]))
+}
+
+// tokenize splits a column definition into tokens.
+func tokenize(s string) []string {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, s[i:i+1])
+			i++
+			continue
+		}
+		end := i + 1
+		switch c {
+		case '\'', '"', '`', '[':
+			q := c
+			if q == '[' {
+				q = ']'
+			}
+			// quotes are escaped by doubling
+			for end < len(s) && (s[end] != q || end+1 < len(s) && s[end+1] == q) {
+				if s[end] == q {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(s))
+		default:
+			for end < len(s) && !strings.ContainsRune(" \t\n\r(),", rune(s[end])) {
+				end++
+			}
+		}
+		tokens = append(tokens, s[i:end])
+		i = end
+	}
+	return tokens
+}
+
+// defaultValue returns the DEFAULT value of a column definition.
+func defaultValue(tokens []string) any {
+	for i := 0; i < len(tokens)-1; i++ {
+		if !strings.EqualFold(tokens[i], "DEFAULT") {
+			continue
+		}
+		v := tokens[i+1]
+		if v == "(" && i+2 < len(tokens) {
+			v = tokens[i+2]
+		}
+		switch {
+		case strings.HasPrefix(v, "'"):
+			return strings.ReplaceAll(strings.Trim(v, "'"), "''", "'")
+		case strings.EqualFold(v, "NULL"):
+			return nil
+		}
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+		return nil
+	}
+	return nil
+}
+
+// unquote unquotes an identifier.
+func unquote(s string) string {
+	if len(s) > 1 {
+		switch s[0] {
+		case '"', '`', '\'':
+			if s[len(s)-1] == s[0] {
+				q := s[:1]
+				return strings.ReplaceAll(s[1:len(s)-1], q+q, q)
+			}
+		case '[':
+			if s[len(s)-1] == ']' {
+				return s[1 : len(s)-1]
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,493 @@
+// Package sqlite is a minimal, read-only reader for sqlite3 database files,
+// used to read cookie databases when no sqlite3 driver has been imported.
+//
+// Only rowid tables are supported. The write-ahead log (if any) is ignored,
+// matching a database opened with the immutable=1 parameter, unless applied
+// with [DB.ApplyWAL].
+package sqlite
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf16"
+)
+
+// Error is an error.
+type Error string
+
+// Error satisfies the error interface.
+func (err Error) Error() string {
+	return string(err)
+}
+
+// Error values.
+const (
+	// ErrNotDatabase is the not a database error. The message matches the
+	// sqlite3 error, so that the error is identified the same way as the
+	// errors returned by sqlite3 drivers.
+	ErrNotDatabase Error = "file is not a database"
+	// ErrMalformed is the malformed database error. The message matches the
+	// sqlite3 error.
+	ErrMalformed Error = "database disk image is malformed"
+	// ErrNoTable is the no such table error.
+	ErrNoTable Error = "no such table"
+)
+
+// maxDepth is the maximum b-tree depth, guarding against cycles in malformed
+// databases.
+const maxDepth = 64
+
+// DB is a sqlite3 database.
+type DB struct {
+	buf      []byte
+	pageSize int
+	usable   int
+	order    binary.ByteOrder
+	// pages are the pages committed to the write-ahead log
+	pages map[int][]byte
+	// npages is the number of pages in the database
+	npages int
+}
+
+// Open reads the sqlite3 database file.
+func Open(name string) (*DB, error) {
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return New(buf)
+}
+
+// OpenLive reads the sqlite3 database file, applying its write-ahead log (if
+// any). See [DB.ApplyWAL].
+func OpenLive(name string) (*DB, error) {
+	db, err := Open(name)
+	if err != nil {
+		return nil, err
+	}
+	wal, err := os.ReadFile(name + "-wal")
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return db, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := db.ApplyWAL(wal); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// New creates a database from the contents of a sqlite3 database file.
+func New(buf []byte) (*DB, error) {
+	if len(buf) < 100 || string(buf[:16]) != "SQLite format 3\x00" {
+		return nil, ErrNotDatabase
+	}
+	pageSize := int(binary.BigEndian.Uint16(buf[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, ErrNotDatabase
+	}
+	usable := pageSize - int(buf[20])
+	if usable < 480 {
+		return nil, ErrMalformed
+	}
+	db := &DB{
+		buf:      buf,
+		pageSize: pageSize,
+		usable:   usable,
+		npages:   len(buf) / pageSize,
+	}
+	switch enc := binary.BigEndian.Uint32(buf[56:60]); enc {
+	case 0, 1:
+	case 2:
+		db.order = binary.LittleEndian
+	case 3:
+		db.order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("%w: invalid text encoding %d", ErrMalformed, enc)
+	}
+	return db, nil
+}
+
+// UserVersion returns the database's user version.
+func (db *DB) UserVersion() int {
+	return int(int32(binary.BigEndian.Uint32(db.buf[60:64])))
+}
+
+// ApplyWAL applies the transactions committed to the write-ahead log (the
+// contents of the database's -wal file), as sqlite3 does when opening a
+// database in WAL mode. Frames following the last valid commit are ignored.
+func (db *DB) ApplyWAL(wal []byte) error {
+	if len(wal) < 32 {
+		return nil
+	}
+	var order binary.ByteOrder
+	switch magic := binary.BigEndian.Uint32(wal); magic {
+	case 0x377f0682:
+		order = binary.LittleEndian
+	case 0x377f0683:
+		order = binary.BigEndian
+	default:
+		return fmt.Errorf("%w: invalid wal header", ErrMalformed)
+	}
+	if int(binary.BigEndian.Uint32(wal[8:])) != db.pageSize {
+		return fmt.Errorf("%w: wal page size mismatch", ErrMalformed)
+	}
+	s0, s1 := walChecksum(order, 0, 0, wal[:24])
+	if s0 != binary.BigEndian.Uint32(wal[24:]) || s1 != binary.BigEndian.Uint32(wal[28:]) {
+		// invalid header, the log is ignored by sqlite3
+		return nil
+	}
+	salt := wal[16:24]
+	pages, frame := make(map[int][]byte), 24+db.pageSize
+	for off := 32; off+frame <= len(wal); off += frame {
+		hdr, page := wal[off:off+24], wal[off+24:off+frame]
+		if string(hdr[8:16]) != string(salt) {
+			break
+		}
+		s0, s1 = walChecksum(order, s0, s1, hdr[:8])
+		s0, s1 = walChecksum(order, s0, s1, page)
+		if s0 != binary.BigEndian.Uint32(hdr[16:]) || s1 != binary.BigEndian.Uint32(hdr[20:]) {
+			break
+		}
+		pages[int(binary.BigEndian.Uint32(hdr))] = page
+		// commit frames store the database size in pages
+		if size := int(binary.BigEndian.Uint32(hdr[4:])); size != 0 {
+			if db.pages == nil {
+				db.pages = make(map[int][]byte)
+			}
+			for n, page := range pages {
+				db.pages[n] = page
+			}
+			clear(pages)
+			db.npages = size
+		}
+	}
+	return nil
+}
+
+// WriteTo writes the database (including the transactions applied from its
+// write-ahead log) to w, as a sqlite3 database file in rollback journal mode.
+func (db *DB) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	for i := 1; i <= db.npages; i++ {
+		page, ok := db.pages[i]
+		if !ok {
+			start := (i - 1) * db.pageSize
+			if start+db.pageSize > len(db.buf) {
+				return n, fmt.Errorf("%w: invalid page %d", ErrMalformed, i)
+			}
+			page = db.buf[start : start+db.pageSize]
+		}
+		if i == 1 {
+			// set the file format to rollback journal mode, and the database
+			// size (valid for the file change counter)
+			page = slices.Clone(page)
+			page[18], page[19] = 1, 1
+			binary.BigEndian.PutUint32(page[28:], uint32(db.npages))
+			copy(page[92:96], page[24:28])
+		}
+		m, err := w.Write(page)
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// walChecksum computes the cumulative checksum of the write-ahead log data.
+func walChecksum(order binary.ByteOrder, s0, s1 uint32, buf []byte) (uint32, uint32) {
+	for i := 0; i+8 <= len(buf); i += 8 {
+		s0 += order.Uint32(buf[i:]) + s1
+		s1 += order.Uint32(buf[i+4:]) + s0
+	}
+	return s0, s1
+}
+
+// Table returns the named table.
+func (db *DB) Table(name string) (*Table, error) {
+	schema := &Table{
+		db:      db,
+		root:    1,
+		rowid:   -1,
+		Name:    "sqlite_schema",
+		Columns: []string{"type", "name", "tbl_name", "rootpage", "sql"},
+	}
+	var table *Table
+	err := schema.Scan(func(_ int64, v []any) error {
+		if typ, _ := v[0].(string); typ != "table" {
+			return nil
+		}
+		if n, _ := v[1].(string); !strings.EqualFold(n, name) {
+			return nil
+		}
+		root, _ := v[3].(int64)
+		sql, _ := v[4].(string)
+		var err error
+		table, err = parseTable(db, name, int(root), sql)
+		return err
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case table == nil:
+		return nil, fmt.Errorf("%w: %s", ErrNoTable, name)
+	}
+	return table, nil
+}
+
+// Table is a sqlite3 table.
+type Table struct {
+	db   *DB
+	root int
+	// rowid is the index of the INTEGER PRIMARY KEY column, which is stored
+	// as the row's rowid
+	rowid int
+	// Name is the table name.
+	Name string
+	// Columns are the table's column names.
+	Columns []string
+	// defaults are the column default values, used for rows written before
+	// a column was added to the table
+	defaults []any
+}
+
+// Scan calls f with the rowid and values of each row in the table, in the
+// order of the table's columns. Values are nil, int64, float64, string or
+// []byte.
+func (t *Table) Scan(f func(int64, []any) error) error {
+	return t.db.walk(t.root, 0, func(rowid int64, payload []byte) error {
+		v, err := t.db.record(payload, len(t.Columns))
+		if err != nil {
+			return err
+		}
+		for i := len(v); i < len(t.Columns); i++ {
+			var def any
+			if i < len(t.defaults) {
+				def = t.defaults[i]
+			}
+			v = append(v, def)
+		}
+		if t.rowid != -1 {
+			v[t.rowid] = rowid
+		}
+		return f(rowid, v[:len(t.Columns)])
+	})
+}
+
+// page returns the contents of page n, and the offset of its b-tree header.
+func (db *DB) page(n int) ([]byte, int, error) {
+	off := 0
+	if n == 1 {
+		off = 100
+	}
+	if n < 1 || n > db.npages {
+		return nil, 0, fmt.Errorf("%w: invalid page %d", ErrMalformed, n)
+	}
+	if buf, ok := db.pages[n]; ok {
+		return buf[:db.usable], off, nil
+	}
+	start := (n - 1) * db.pageSize
+	if start+db.pageSize > len(db.buf) {
+		return nil, 0, fmt.Errorf("%w: invalid page %d", ErrMalformed, n)
+	}
+	return db.buf[start : start+db.usable], off, nil
+}
+
+// walk walks the table b-tree rooted at page n, calling f with the rowid and
+// payload of each row.
+func (db *DB) walk(n, depth int, f func(int64, []byte) error) error {
+	if depth > maxDepth {
+		return fmt.Errorf("%w: b-tree too deep", ErrMalformed)
+	}
+	page, off, err := db.page(n)
+	if err != nil {
+		return err
+	}
+	if off+12 > len(page) {
+		return ErrMalformed
+	}
+	typ, count := page[off], int(binary.BigEndian.Uint16(page[off+3:]))
+	cells := off + 8
+	if typ == 0x05 {
+		cells = off + 12
+	}
+	if cells+2*count > len(page) {
+		return ErrMalformed
+	}
+	for i := range count {
+		cell := int(binary.BigEndian.Uint16(page[cells+2*i:]))
+		if cell >= len(page) {
+			return ErrMalformed
+		}
+		switch typ {
+		case 0x05: // interior
+			if cell+4 > len(page) {
+				return ErrMalformed
+			}
+			if err := db.walk(int(binary.BigEndian.Uint32(page[cell:])), depth+1, f); err != nil {
+				return err
+			}
+		case 0x0d: // leaf
+			size, m := varint(page[cell:])
+			rowid, k := varint(page[cell+m:])
+			if m == 0 || k == 0 {
+				return ErrMalformed
+			}
+			payload, err := db.payload(page, cell+m+k, int(size))
+			if err != nil {
+				return err
+			}
+			if err := f(int64(rowid), payload); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: invalid table b-tree page %d (type %d)", ErrMalformed, n, typ)
+		}
+	}
+	if typ == 0x05 {
+		return db.walk(int(binary.BigEndian.Uint32(page[off+8:])), depth+1, f)
+	}
+	return nil
+}
+
+// payload returns the payload of size stored at offset off in the page,
+// following overflow pages as needed.
+func (db *DB) payload(page []byte, off, size int) ([]byte, error) {
+	if size < 0 || size > db.npages*db.pageSize {
+		return nil, ErrMalformed
+	}
+	// determine amount stored locally
+	local, x := size, db.usable-35
+	if size > x {
+		m := (db.usable-12)*32/255 - 23
+		local = m + (size-m)%(db.usable-4)
+		if local > x {
+			local = m
+		}
+	}
+	if off+local > len(page) {
+		return nil, ErrMalformed
+	}
+	if local == size {
+		return page[off : off+local], nil
+	}
+	if off+local+4 > len(page) {
+		return nil, ErrMalformed
+	}
+	// read overflow pages
+	buf := append(make([]byte, 0, size), page[off:off+local]...)
+	next, seen := int(binary.BigEndian.Uint32(page[off+local:])), make(map[int]bool)
+	for len(buf) < size {
+		if next == 0 || seen[next] {
+			return nil, fmt.Errorf("%w: invalid overflow page", ErrMalformed)
+		}
+		seen[next] = true
+		overflow, _, err := db.page(next)
+		if err != nil {
+			return nil, err
+		}
+		next = int(binary.BigEndian.Uint32(overflow))
+		buf = append(buf, overflow[4:min(len(overflow), 4+size-len(buf))]...)
+	}
+	return buf, nil
+}
+
+// record decodes the values of a record.
+func (db *DB) record(buf []byte, n int) ([]any, error) {
+	size, m := varint(buf)
+	if m == 0 || int(size) > len(buf) || int(size) < m {
+		return nil, ErrMalformed
+	}
+	hdr, body := buf[m:size], buf[size:]
+	v := make([]any, 0, n)
+	for len(hdr) != 0 {
+		typ, k := varint(hdr)
+		if k == 0 {
+			return nil, ErrMalformed
+		}
+		hdr = hdr[k:]
+		var l int
+		switch {
+		case typ == 0, typ == 8, typ == 9:
+		case typ <= 4:
+			l = int(typ)
+		case typ == 5:
+			l = 6
+		case typ == 6, typ == 7:
+			l = 8
+		case typ >= 12:
+			l = int(typ-12) / 2
+		default:
+			return nil, fmt.Errorf("%w: invalid serial type %d", ErrMalformed, typ)
+		}
+		if l > len(body) {
+			return nil, ErrMalformed
+		}
+		b := body[:l]
+		body = body[l:]
+		switch {
+		case typ == 0:
+			v = append(v, nil)
+		case typ == 8:
+			v = append(v, int64(0))
+		case typ == 9:
+			v = append(v, int64(1))
+		case typ <= 6:
+			i := int64(int8(b[0]))
+			for _, c := range b[1:] {
+				i = i<<8 | int64(c)
+			}
+			v = append(v, i)
+		case typ == 7:
+			v = append(v, math.Float64frombits(binary.BigEndian.Uint64(b)))
+		case typ%2 == 0:
+			v = append(v, append([]byte(nil), b...))
+		default:
+			v = append(v, db.text(b))
+		}
+	}
+	return v, nil
+}
+
+// text decodes text in the database's encoding.
+func (db *DB) text(b []byte) string {
+	if db.order == nil {
+		return string(b)
+	}
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = db.order.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(u))
+}
+
+// varint decodes a sqlite3 variable length integer, returning the value and
+// the number of bytes read. Returns 0 bytes read when buf is too short.
+func varint(buf []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9; i++ {
+		if i >= len(buf) {
+			return 0, 0
+		}
+		if i == 8 {
+			return v<<8 | uint64(buf[i]), 9
+		}
+		v = v<<7 | uint64(buf[i]&0x7f)
+		if buf[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return v, 9
+}
@@ -0,0 +1,345 @@
+package sqlite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// readFile reads the test file.
+func readFile(t *testing.T, name string) []byte {
+	t.Helper()
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return buf
+}
+
+// scan returns the rows of the table t, keyed by rowid.
+func scan(db *DB) (map[int64][]any, error) {
+	table, err := db.Table("t")
+	if err != nil {
+		return nil, err
+	}
+	rows := make(map[int64][]any)
+	err = table.Scan(func(rowid int64, v []any) error {
+		rows[rowid] = v
+		return nil
+	})
+	return rows, err
+}
+
+func TestWalk(t *testing.T) {
+	db, err := Open("testdata/test.sqlite")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	table, err := db.Table("t")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if exp := []string{"id", "name", "value"}; !slices.Equal(table.Columns, exp) {
+		t.Errorf("expected %v, got: %v", exp, table.Columns)
+	}
+	// the root page is an interior page
+	page, off, err := db.page(table.root)
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case page[off] != 0x05:
+		t.Errorf("expected interior page, got type %d", page[off])
+	}
+	var rowids []int64
+	err = table.Scan(func(rowid int64, v []any) error {
+		rowids = append(rowids, rowid)
+		switch {
+		case v[0] != rowid:
+			t.Errorf("expected id %d, got: %v", rowid, v[0])
+		case rowid == 201 && !bytes.Equal(v[2].([]byte), bytes.Repeat([]byte{'0'}, 2000)):
+			t.Errorf("expected 2000 byte overflow value, got: %d bytes", len(v[2].([]byte)))
+		case rowid == 200 && v[1] != "row 200":
+			t.Errorf("expected %q, got: %v", "row 200", v[1])
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(rowids) != 201 || !slices.IsSorted(rowids) {
+		t.Errorf("expected 201 sorted rowids, got: %v", rowids)
+	}
+}
+
+func TestWalkIndex(t *testing.T) {
+	db, err := Open("testdata/test.sqlite")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// index b-tree pages are not table b-tree pages
+	if err := db.walk(3, 0, func(int64, []byte) error { return nil }); !errors.Is(err, ErrMalformed) {
+		t.Errorf("expected %v, got: %v", ErrMalformed, err)
+	}
+}
+
+func TestWalkMalformed(t *testing.T) {
+	buf := readFile(t, "testdata/test.sqlite")
+	tests := []struct {
+		name string
+		f    func([]byte) []byte
+	}{
+		{"truncated", func(buf []byte) []byte {
+			return buf[:len(buf)-512]
+		}},
+		{"cyclic interior", func(buf []byte) []byte {
+			// point the root page's right-most child to itself
+			binary.BigEndian.PutUint32(buf[512+8:], 2)
+			return buf
+		}},
+		{"invalid child", func(buf []byte) []byte {
+			binary.BigEndian.PutUint32(buf[512+8:], 1000)
+			return buf
+		}},
+		{"invalid cell", func(buf []byte) []byte {
+			binary.BigEndian.PutUint16(buf[512+12:], 0xffff)
+			return buf
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := New(test.f(slices.Clone(buf)))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if _, err := scan(db); !errors.Is(err, ErrMalformed) {
+				t.Errorf("expected %v, got: %v", ErrMalformed, err)
+			}
+		})
+	}
+}
+
+// overflowDB returns a database with 512 byte pages, and pages 2, 3 and 4
+// storing the overflow of a 2000 byte payload, returning the payload and the
+// page storing the local part of the payload.
+func overflowDB() (*DB, []byte, []byte) {
+	payload := make([]byte, 2000)
+	for i := range payload {
+		payload[i] = byte(i % 251)
+	}
+	// 476 bytes are stored locally, followed by 3 overflow pages of 508
+	// bytes
+	page := append(slices.Clone(payload[:476]), 0, 0, 0, 2)
+	buf := make([]byte, 4*512)
+	for i, n := range []int{3, 4, 0} {
+		off := (i + 1) * 512
+		binary.BigEndian.PutUint32(buf[off:], uint32(n))
+		copy(buf[off+4:off+512], payload[476+i*508:])
+	}
+	return &DB{buf: buf, pageSize: 512, usable: 512, npages: 4}, payload, page
+}
+
+func TestPayload(t *testing.T) {
+	db, payload, page := overflowDB()
+	buf, err := db.payload(page, 0, len(payload))
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case !bytes.Equal(buf, payload):
+		t.Errorf("expected overflow payload, got: %d bytes", len(buf))
+	}
+	// local payload
+	buf, err = db.payload(payload, 10, 100)
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case !bytes.Equal(buf, payload[10:110]):
+		t.Errorf("expected local payload, got: %v", buf)
+	}
+	tests := []struct {
+		name string
+		f    func([]byte, []byte)
+		size int
+	}{
+		{"cyclic", func(buf, _ []byte) { binary.BigEndian.PutUint32(buf[2*512:], 2) }, len(payload)},
+		{"truncated chain", func(buf, _ []byte) { binary.BigEndian.PutUint32(buf[2*512:], 0) }, len(payload)},
+		{"invalid page", func(_, page []byte) { binary.BigEndian.PutUint32(page[476:], 9) }, len(payload)},
+		{"short page", func([]byte, []byte) {}, 4 * 512 * 2},
+		{"negative size", func([]byte, []byte) {}, -1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, _, page := overflowDB()
+			test.f(db.buf, page)
+			if _, err := db.payload(page, 0, test.size); !errors.Is(err, ErrMalformed) {
+				t.Errorf("expected %v, got: %v", ErrMalformed, err)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	hdr := []byte{13, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 18, 17}
+	body := []byte{
+		0xff,       // int8
+		0x01, 0x02, // int16
+		0xff, 0xff, 0xfe, // int24
+		0x00, 0x01, 0x00, 0x00, // int32
+		0x80, 0x00, 0x00, 0x00, 0x00, 0x00, // int48
+		0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, // int64
+	}
+	body = binary.BigEndian.AppendUint64(body, math.Float64bits(1.5))
+	body = append(body, 1, 2, 3, 'h', 'i')
+	exp := []any{nil, int64(-1), int64(258), int64(-2), int64(65536), int64(-1 << 47), int64(math.MaxInt64), 1.5, int64(0), int64(1), []byte{1, 2, 3}, "hi"}
+	db := &DB{}
+	v, err := db.record(append(hdr, body...), len(exp))
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case !reflect.DeepEqual(v, exp):
+		t.Errorf("expected %v, got: %v", exp, v)
+	}
+	// utf-16le text
+	db.order = binary.LittleEndian
+	v, err = db.record([]byte{2, 25, 'h', 0, 'i', 0, 0xac, 0x20}, 1)
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case !reflect.DeepEqual(v, []any{"hi€"}):
+		t.Errorf("expected %v, got: %v", []any{"hi€"}, v)
+	}
+	tests := []struct {
+		name string
+		buf  []byte
+	}{
+		{"empty", nil},
+		{"header size", []byte{5, 1}},
+		{"short header", []byte{0}},
+		{"serial type", []byte{2, 10}},
+		{"short body", []byte{2, 6, 1, 2, 3}},
+		{"short varint", []byte{2, 0x81}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := (&DB{}).record(test.buf, 1); !errors.Is(err, ErrMalformed) {
+				t.Errorf("expected %v, got: %v", ErrMalformed, err)
+			}
+		})
+	}
+}
+
+// resum recomputes the checksums of the write-ahead log, using the byte order
+// of its magic number.
+func resum(wal []byte) []byte {
+	var order binary.ByteOrder = binary.LittleEndian
+	if binary.BigEndian.Uint32(wal) == 0x377f0683 {
+		order = binary.BigEndian
+	}
+	s0, s1 := walChecksum(order, 0, 0, wal[:24])
+	binary.BigEndian.PutUint32(wal[24:], s0)
+	binary.BigEndian.PutUint32(wal[28:], s1)
+	for off := 32; off+24+512 <= len(wal); off += 24 + 512 {
+		s0, s1 = walChecksum(order, s0, s1, wal[off:off+8])
+		s0, s1 = walChecksum(order, s0, s1, wal[off+24:off+24+512])
+		binary.BigEndian.PutUint32(wal[off+16:], s0)
+		binary.BigEndian.PutUint32(wal[off+20:], s1)
+	}
+	return wal
+}
+
+func TestApplyWAL(t *testing.T) {
+	buf, wal := readFile(t, "testdata/wal.sqlite"), readFile(t, "testdata/wal.sqlite-wal")
+	// the log has the first transaction in its first frame, and the second
+	// in the following two frames
+	last := len(wal) - 24 - 512
+	tests := []struct {
+		name string
+		f    func([]byte) []byte
+		exp  []string
+	}{
+		{"none", func([]byte) []byte { return nil }, []string{"row 1"}},
+		{"committed", func(wal []byte) []byte { return wal }, []string{"wal 2", "wal 1", "wal 2"}},
+		{"big endian", func(wal []byte) []byte {
+			binary.BigEndian.PutUint32(wal, 0x377f0683)
+			return resum(wal)
+		}, []string{"wal 2", "wal 1", "wal 2"}},
+		{"uncommitted", func(wal []byte) []byte {
+			binary.BigEndian.PutUint32(wal[last+4:], 0)
+			return resum(wal)
+		}, []string{"row 1", "wal 1"}},
+		{"bad checksum", func(wal []byte) []byte {
+			wal[last+24+100] ^= 0xff
+			return wal
+		}, []string{"row 1", "wal 1"}},
+		{"salt", func(wal []byte) []byte {
+			wal[last+8] ^= 0xff
+			return resum(wal)
+		}, []string{"row 1", "wal 1"}},
+		{"bad header checksum", func(wal []byte) []byte {
+			wal[24] ^= 0xff
+			return wal
+		}, []string{"row 1"}},
+		{"truncated", func(wal []byte) []byte {
+			return wal[:len(wal)-1]
+		}, []string{"row 1", "wal 1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := New(slices.Clone(buf))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if err := db.ApplyWAL(test.f(slices.Clone(wal))); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			rows, err := scan(db)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			var names []string
+			for _, rowid := range []int64{1, 1001, 1002} {
+				if v, ok := rows[rowid]; ok {
+					names = append(names, v[1].(string))
+				}
+			}
+			if !slices.Equal(names, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, names)
+			}
+			// the database written with the applied transactions is the
+			// same
+			var w bytes.Buffer
+			if _, err := db.WriteTo(&w); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			db, err = New(w.Bytes())
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if written, err := scan(db); err != nil || !reflect.DeepEqual(written, rows) {
+				t.Errorf("expected written rows to match, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestApplyWALInvalid(t *testing.T) {
+	db, err := Open("testdata/wal.sqlite")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	wal := readFile(t, "testdata/wal.sqlite-wal")
+	bad := slices.Clone(wal)
+	bad[0] = 0
+	if err := db.ApplyWAL(bad); !errors.Is(err, ErrMalformed) {
+		t.Errorf("expected %v, got: %v", ErrMalformed, err)
+	}
+	bad = slices.Clone(wal)
+	binary.BigEndian.PutUint32(bad[8:], 1024)
+	if err := db.ApplyWAL(bad); !errors.Is(err, ErrMalformed) {
+		t.Errorf("expected %v, got: %v", ErrMalformed, err)
+	}
+}
@@ -0,0 +1,13 @@
+#!/bin/bash
+
+# gen.sh regenerates the test databases from test.sql and wal.sql.
+
+SRC=$(realpath $(cd -P "$(dirname "${BASH_SOURCE[0]}")" && pwd))
+
+set -ex
+
+cd $SRC
+rm -f test.sqlite wal.sqlite wal.sqlite-wal work.sqlite work.sqlite-wal
+sqlite3 test.sqlite < test.sql
+sqlite3 work.sqlite < wal.sql
+rm -f work.sqlite work.sqlite-wal work.sqlite-shm
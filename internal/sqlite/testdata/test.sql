@@ -0,0 +1,20 @@
+-- test.sql is the test database, with small pages so that the table b-tree
+-- has interior pages, and a row with a value stored on overflow pages.
+-- Regenerate test.sqlite with gen.sh.
+
+PRAGMA page_size = 512;
+
+CREATE TABLE t (
+  id INTEGER PRIMARY KEY,
+  name TEXT NOT NULL,
+  value BLOB
+);
+
+CREATE INDEX t_name ON t (name);
+
+-- rows 1 to 200, with 40 byte values
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 200)
+INSERT INTO t (id, name, value) SELECT i, 'row ' || i, printf('%040d', i) FROM n;
+
+-- row 201, with a 2000 byte value
+INSERT INTO t (id, name, value) VALUES (201, 'overflow', CAST(hex(zeroblob(1000)) AS BLOB));
@@ -0,0 +1,30 @@
+-- wal.sql is the test database in WAL mode, with two transactions committed
+-- to the write-ahead log. The database and its write-ahead log are copied
+-- before the log is checkpointed on close. Regenerate wal.sqlite and
+-- wal.sqlite-wal with gen.sh.
+
+PRAGMA page_size = 512;
+PRAGMA journal_mode = WAL;
+PRAGMA wal_autocheckpoint = 0;
+
+CREATE TABLE t (
+  id INTEGER PRIMARY KEY,
+  name TEXT NOT NULL
+);
+
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 50)
+INSERT INTO t (id, name) SELECT i, 'row ' || i FROM n;
+
+PRAGMA wal_checkpoint(TRUNCATE);
+
+-- first transaction
+INSERT INTO t (id, name) VALUES (1001, 'wal 1');
+
+-- second transaction
+BEGIN;
+INSERT INTO t (id, name) VALUES (1002, 'wal 2');
+UPDATE t SET name = 'wal 2' WHERE id = 1;
+COMMIT;
+
+.shell cp work.sqlite wal.sqlite
+.shell cp work.sqlite-wal wal.sqlite-wal
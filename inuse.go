@@ -0,0 +1,20 @@
+package ffcookies
+
+import (
+	"path/filepath"
+)
+
+// ProfileInUse returns true when the Firefox profile directory is in use by a
+// running Firefox, as determined by the profile's lock files.
+func ProfileInUse(dir string) bool {
+	return profileInUse(dir)
+}
+
+// fileInUse returns true when the profile directory of the sqlite3 file is in
+// use by a running Firefox.
+func fileInUse(file string) bool {
+	if file == "" {
+		return false
+	}
+	return profileInUse(filepath.Dir(dbPath(file)))
+}
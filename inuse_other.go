@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package ffcookies
+
+// profileInUse returns false, as profile locks are not supported on this
+// platform.
+func profileInUse(string) bool {
+	return false
+}
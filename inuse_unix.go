@@ -0,0 +1,42 @@
+//go:build unix
+
+package ffcookies
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// profileInUse returns true when the profile directory's .parentlock file is
+// locked by another process, or the profile directory's lock symlink refers to
+// a running process.
+func profileInUse(dir string) bool {
+	if f, err := os.Open(filepath.Join(dir, ".parentlock")); err == nil {
+		defer f.Close()
+		lk := syscall.Flock_t{
+			Type: syscall.F_WRLCK,
+		}
+		if err := syscall.FcntlFlock(f.Fd(), syscall.F_GETLK, &lk); err == nil && lk.Type != syscall.F_UNLCK {
+			return true
+		}
+	}
+	// the lock symlink's target is of the form ip:+pid
+	target, err := os.Readlink(filepath.Join(dir, "lock"))
+	if err != nil {
+		return false
+	}
+	_, s, ok := strings.Cut(target, ":+")
+	if !ok {
+		return false
+	}
+	pid, err := strconv.Atoi(s)
+	if err != nil || pid <= 0 {
+		return false
+	}
+	err = syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
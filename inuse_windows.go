@@ -0,0 +1,24 @@
+//go:build windows
+
+package ffcookies
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// errSharingViolation is the ERROR_SHARING_VIOLATION error.
+const errSharingViolation syscall.Errno = 32
+
+// profileInUse returns true when the profile directory's parent.lock file is
+// held open by another process (Firefox opens it without sharing).
+func profileInUse(dir string) bool {
+	f, err := os.Open(filepath.Join(dir, "parent.lock"))
+	if err != nil {
+		return errors.Is(err, errSharingViolation)
+	}
+	_ = f.Close()
+	return false
+}
@@ -0,0 +1,105 @@
+package ffcookies
+
+import (
+	"context"
+	"iter"
+	"net/http"
+
+	"github.com/kenshaw/ffcookies/models"
+)
+
+// CookiesIter returns an iterator over the cookies read using the provided
+// options (see [ReadCookies]). Rows are read and converted as the iterator is
+// iterated, and the database is closed when iteration stops. Iteration stops
+// at the first error, including when the context is canceled.
+//
+// Cookies are selected from the same cookie source as [ReadCookies] (see
+// [WithSource] and [RegisterSource]), and the cookies of a selected cookie
+// source are read before iterating. Reads of a busy database are retried (see
+// [WithRetry]) until the first cookie has been yielded.
+//
+// As cookies are not collected, errors for cookies with empty names (see
+// [WithEmptyNameError]) are yielded after all other cookies, and an error for
+// a SameSite=None cookie without Secure (see [SameSiteNoneFlag]) is yielded
+// in place of the cookie.
+func CookiesIter(ctx context.Context, opts ...Option) iter.Seq2[*http.Cookie, error] {
+	return func(yield func(*http.Cookie, error) bool) {
+		o := newOptions(opts...)
+		if err := o.selectSource(); err != nil {
+			yield(nil, err)
+			return
+		}
+		if o.source != nil || o.dedupeOrigins {
+			// resolving cookies requires all of the cookies
+			cookies, err := o.readCookies(ctx)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, cookie := range cookies {
+				if !yield(cookie, nil) {
+					return
+				}
+			}
+			return
+		}
+		file, err := o.profileFile(o.profile)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		src, closeSrc, err := o.open(ctx, file)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer closeSrc()
+		var empty []*models.Cookie
+		var yielded, stopped bool
+		err = o.retry(ctx, func() error {
+			empty = empty[:0]
+			for c, err := range src.cookies(ctx, o.query(o.host)) {
+				switch {
+				case err != nil && !yielded:
+					// retry when busy
+					return err
+				case err != nil:
+					yield(nil, dbError(file, err))
+					stopped = true
+					return nil
+				case o.emptyNameErr && c.Name == "":
+					empty = append(empty, c)
+					continue
+				case o.partitionSite != nil && !partitionMatch(c, o.partitionSite, o.includePartitioned):
+					continue
+				case o.partitionKey != nil && !partitionKeyMatch(c, o.partitionKey):
+					continue
+				}
+				cookie := o.convert(c)
+				if cookie == nil {
+					continue
+				}
+				if err := o.sameSiteNone.apply([]*http.Cookie{cookie}); err != nil {
+					yield(nil, err)
+					stopped = true
+					return nil
+				}
+				yielded = true
+				if !yield(cookie, nil) {
+					stopped = true
+					return nil
+				}
+			}
+			return nil
+		})
+		switch {
+		case err != nil:
+			yield(nil, dbError(file, err))
+		case stopped:
+		default:
+			if err := checkEmptyNames(empty); err != nil {
+				yield(nil, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package ffcookies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+
+	"github.com/kenshaw/ffcookies/models"
+)
+
+// LockStrategy is the strategy for reading a cookie database that is busy or
+// locked by a running Firefox.
+//
+// The database is opened as an immutable snapshot by default (see
+// [DefaultOpenParams]), which is never locked. Busy and locked errors occur
+// when reading with other open parameters (see [WithOpenParams]), such as
+// when reading the live database.
+type LockStrategy int
+
+// LockStrategy values.
+const (
+	// LockFail fails the read, unless retries have been configured with
+	// [WithRetry].
+	LockFail LockStrategy = iota
+	// LockRetry retries the read, using [DefaultRetryPolicy] unless a retry
+	// policy has been configured with [WithRetry].
+	LockRetry
+	// LockCopy retries the read as with LockRetry, and then copies the
+	// database (and its write-ahead log) to a temporary directory and reads
+	// the copy.
+	LockCopy
+	// LockAuto reads a copy of the database (as with LockCopy) without
+	// retrying when the database's profile is in use by a running Firefox
+	// (see [ProfileInUse]), and otherwise behaves as LockCopy. Reading a
+	// copy avoids errors caused by Firefox writing to the database while it
+	// is read.
+	LockAuto
+)
+
+// String satisfies the fmt.Stringer interface.
+func (strategy LockStrategy) String() string {
+	switch strategy {
+	case LockFail:
+		return "fail"
+	case LockRetry:
+		return "retry"
+	case LockCopy:
+		return "copy"
+	case LockAuto:
+		return "auto"
+	}
+	return fmt.Sprintf("LockStrategy(%d)", int(strategy))
+}
+
+// retries returns the retry policy for the lock strategy.
+func (o *options) retries() RetryPolicy {
+	if o.lockStrategy != LockFail && o.retryPolicy.Attempts < 2 {
+		return DefaultRetryPolicy
+	}
+	return o.retryPolicy
+}
+
+// openCopy opens a copy of the sqlite3 file and its write-ahead log.
+func (o *options) openCopy(ctx context.Context, file string) (source, func() error, error) {
+	o.logger.Debug("database locked, reading a copy", "file", file)
+	name, closeFile, err := o.liveCopy(dbPath(file))
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := o.openDB(ctx, name)
+	if err != nil {
+		closeFile()
+		return nil, nil, err
+	}
+	return dbSource{db}, func() error {
+		defer closeFile()
+		return db.Close()
+	}, nil
+}
+
+// copySource is a cookie source that reads a copy of the database when the
+// database is locked. See [LockCopy].
+type copySource struct {
+	o    *options
+	file string
+	src  source
+	// mu protects the copy
+	mu    sync.Mutex
+	copy  source
+	close func() error
+}
+
+// cookies satisfies the source interface.
+func (src *copySource) cookies(ctx context.Context, q *query) iter.Seq2[*models.Cookie, error] {
+	return func(yield func(*models.Cookie, error) bool) {
+		n := 0
+		for c, err := range src.source().cookies(ctx, q) {
+			if err != nil && n == 0 && isBusy(err) {
+				break
+			}
+			if !yield(c, err) || err != nil {
+				return
+			}
+			n++
+		}
+		if n != 0 {
+			return
+		}
+		copy, err := src.openCopy(ctx)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for c, err := range copy.cookies(ctx, q) {
+			if !yield(c, err) {
+				return
+			}
+		}
+	}
+}
+
+// source returns the copy of the database when opened, or the database.
+func (src *copySource) source() source {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	if src.copy != nil {
+		return src.copy
+	}
+	return src.src
+}
+
+// openCopy opens the copy of the database.
+func (src *copySource) openCopy(ctx context.Context) (source, error) {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	if src.copy == nil {
+		var err error
+		if src.copy, src.close, err = src.o.openCopy(ctx, src.file); err != nil {
+			return nil, err
+		}
+	}
+	return src.copy, nil
+}
+
+// closeCopy closes the copy of the database, if opened.
+func (src *copySource) closeCopy() error {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	if src.copy == nil {
+		return nil
+	}
+	err := src.close()
+	src.copy, src.close = nil, nil
+	return err
+}
+
+// lockSource wraps the opened source according to the lock strategy,
+// returning the source and a func closing it.
+func (o *options) lockSource(src source, closeSrc func() error, file string) (source, func() error) {
+	if o.lockStrategy != LockCopy && o.lockStrategy != LockAuto {
+		return src, closeSrc
+	}
+	s := &copySource{
+		o:    o,
+		file: file,
+		src:  src,
+	}
+	return s, func() error {
+		return errors.Join(s.closeCopy(), closeSrc())
+	}
+}
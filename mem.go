@@ -0,0 +1,50 @@
+package ffcookies
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/kenshaw/ffcookies/internal/sqlite"
+)
+
+// ReadBytes reads the cookies for the host from the contents of a sqlite3
+// file, such as a cookie database fetched from object storage. The database
+// is read in memory without a sqlite3 driver, and is never written to disk.
+func ReadBytes(ctx context.Context, data []byte, host string, opts ...Option) ([]*http.Cookie, error) {
+	o := newOptions(opts...)
+	if err := o.resolveContainer(nil, ""); err != nil {
+		return nil, err
+	}
+	src, err := o.openMem("", data, nil)
+	if err != nil {
+		return nil, err
+	}
+	return o.read(ctx, src, "", host)
+}
+
+// ReadReaderAt reads the cookies for the host from the size bytes of a sqlite3
+// file read from r. See [ReadBytes].
+func ReadReaderAt(ctx context.Context, r io.ReaderAt, size int64, host string, opts ...Option) ([]*http.Cookie, error) {
+	buf, err := io.ReadAll(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, err
+	}
+	return ReadBytes(ctx, buf, host, opts...)
+}
+
+// openMem opens the contents of a sqlite3 file (and its write-ahead log, when
+// not empty) in memory, without a sqlite3 driver.
+func (o *options) openMem(name string, buf, wal []byte) (source, error) {
+	o.logger.Debug("reading database without a sqlite driver", "name", name, "live", len(wal) != 0)
+	db, err := sqlite.New(buf)
+	if err != nil {
+		return nil, dbError(name, err)
+	}
+	if len(wal) != 0 {
+		if err := db.ApplyWAL(wal); err != nil {
+			return nil, dbError(name, err)
+		}
+	}
+	return nativeSource{db}, nil
+}
@@ -0,0 +1,130 @@
+package ffcookies
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kenshaw/ffcookies/models"
+)
+
+// MergeJar reads the cookies for the provided Firefox profile name (or the
+// default Firefox profile) for the url, and builds a cookie jar containing
+// them and the extra cookies.
+//
+// Extra cookies take precedence over profile cookies with the same name,
+// domain and path. Domains are compared including any leading dot, so that an
+// extra host-only cookie does not replace a domain cookie (and vice versa).
+// An extra cookie without a domain is treated as a host-only cookie for the
+// url's host, and one without a path as having the path /.
+// When multiple extra cookies collide, the last one wins.
+func MergeJar(ctx context.Context, profile, urlstr string, extra ...*http.Cookie) (http.CookieJar, error) {
+	u, o, cookies, err := readJar(ctx, profile, urlstr)
+	if err != nil {
+		return nil, err
+	}
+	return JarWithPublicSuffixList(u, o.publicSuffixList, mergeCookies(u, cookies, extra)...)
+}
+
+// mergeCookies merges the extra cookies into cookies, replacing cookies with
+// the same name, domain (including any leading dot) and path.
+func mergeCookies(u *url.URL, cookies, extra []*http.Cookie) []*http.Cookie {
+	type key struct {
+		name, domain, path string
+	}
+	keyOf := func(cookie *http.Cookie) key {
+		// the leading dot distinguishes domain cookies from host-only
+		// cookies
+		k := key{cookie.Name, strings.ToLower(cookie.Domain), cookie.Path}
+		if k.domain == "" {
+			k.domain = urlHost(u)
+		}
+		if k.path == "" {
+			k.path = "/"
+		}
+		return k
+	}
+	override := make(map[key]*http.Cookie, len(extra))
+	for _, cookie := range extra {
+		override[keyOf(cookie)] = cookie
+	}
+	var res []*http.Cookie
+	for _, cookie := range cookies {
+		if _, ok := override[keyOf(cookie)]; !ok {
+			res = append(res, cookie)
+		}
+	}
+	for _, cookie := range extra {
+		if override[keyOf(cookie)] == cookie {
+			res = append(res, cookie)
+		}
+	}
+	return res
+}
+
+// ReadProfilesContext reads the cookies for the host from the provided
+// Firefox profiles, merging them. Profiles are resolved the same as with
+// [ReadContext].
+//
+// When multiple profiles have a cookie with the same name, host, path and
+// origin attributes, the most recently accessed cookie wins. When the cookies
+// were last accessed at the same time, the cookie from the profile listed
+// first wins. Cookies that differ only by origin attributes (such as container
+// and partitioned cookies) are kept.
+func ReadProfilesContext(ctx context.Context, profiles []string, host string, opts ...Option) ([]*http.Cookie, error) {
+	o := newOptions(opts...)
+	var res []*models.Cookie
+	for _, profile := range profiles {
+		rows, err := o.profileRows(ctx, profile, host)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rows...)
+	}
+	return o.convertAll(mergeRows(res))
+}
+
+// ReadProfiles reads the cookies for the host from the provided Firefox
+// profiles, merging them. See [ReadProfilesContext].
+func ReadProfiles(profiles []string, host string, opts ...Option) ([]*http.Cookie, error) {
+	return ReadProfilesContext(context.Background(), profiles, host, opts...)
+}
+
+// profileRows reads the rows for the host from the profile.
+func (o *options) profileRows(ctx context.Context, profile, host string) ([]*models.Cookie, error) {
+	file, err := o.profileFile(profile)
+	if err != nil {
+		return nil, err
+	}
+	src, closeSrc, err := o.open(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSrc()
+	return o.rows(ctx, src, file, host)
+}
+
+// mergeRows merges rows with the same name, host, path and origin
+// attributes (the unique key of the moz_cookies table), keeping the most
+// recently accessed row, or the first row when accessed at the same time.
+// The order of the rows is otherwise retained.
+func mergeRows(rows []*models.Cookie) []*models.Cookie {
+	type key struct {
+		name, host, path, originAttributes string
+	}
+	keep := make(map[key]*models.Cookie, len(rows))
+	for _, c := range rows {
+		k := key{c.Name, c.Host, c.Path, c.OriginAttributes}
+		if prev, ok := keep[k]; !ok || c.LastAccessed > prev.LastAccessed {
+			keep[k] = c
+		}
+	}
+	res := make([]*models.Cookie, 0, len(keep))
+	for _, c := range rows {
+		if keep[key{c.Name, c.Host, c.Path, c.OriginAttributes}] == c {
+			res = append(res, c)
+		}
+	}
+	return res
+}
@@ -0,0 +1,65 @@
+package ffcookies
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMergeJar(t *testing.T) {
+	profile, err := filepath.Abs(testDB)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	jar, err := MergeJar(
+		context.Background(), profile, "https://hostonly.test",
+		&http.Cookie{Name: "domain", Value: "extra", Domain: ".hostonly.test", Path: "/"},
+		&http.Cookie{Name: "token", Value: "t"},
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := map[string]string{"domain": "extra", "host": "1", "token": "t"}
+	if values := cookieValues(jarCookies(t, jar, "https://hostonly.test")); !maps.Equal(values, exp) {
+		t.Errorf("expected %v, got: %v", exp, values)
+	}
+}
+
+func TestMergeCookies(t *testing.T) {
+	u, err := url.Parse("https://example.com")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cookies := []*http.Cookie{
+		{Name: "a", Value: "profile", Domain: ".example.com", Path: "/"},
+		{Name: "b", Value: "profile", Domain: "example.com", Path: "/"},
+		{Name: "c", Value: "profile", Domain: "example.com", Path: "/"},
+	}
+	extra := []*http.Cookie{
+		// host-only does not replace a domain cookie
+		{Name: "a", Value: "extra", Domain: "example.com", Path: "/"},
+		// no domain or path replaces a host-only cookie
+		{Name: "b", Value: "extra"},
+		// last wins
+		{Name: "c", Value: "extra1", Domain: "EXAMPLE.COM", Path: "/"},
+		{Name: "c", Value: "extra2", Domain: "example.com", Path: "/"},
+	}
+	res := mergeCookies(u, cookies, extra)
+	var values []string
+	for _, cookie := range res {
+		values = append(values, cookie.Name+"="+cookie.Value+" "+cookie.Domain)
+	}
+	exp := []string{
+		"a=profile .example.com",
+		"a=extra example.com",
+		"b=extra ",
+		"c=extra2 example.com",
+	}
+	if !slices.Equal(values, exp) {
+		t.Errorf("expected %v, got: %v", exp, values)
+	}
+}
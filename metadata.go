@@ -0,0 +1,44 @@
+package ffcookies
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kenshaw/ffcookies/models"
+)
+
+// Metadata attribute names added to a cookie's Unparsed attributes when
+// reading with [WithMetadata].
+const (
+	// MetadataOriginAttributes is the origin attributes metadata attribute.
+	MetadataOriginAttributes = "X-FFCookies-OriginAttributes"
+	// MetadataCreationTime is the creation time metadata attribute, in
+	// microseconds since the epoch.
+	MetadataCreationTime = "X-FFCookies-CreationTime"
+	// MetadataLastAccessed is the last accessed time metadata attribute, in
+	// microseconds since the epoch.
+	MetadataLastAccessed = "X-FFCookies-LastAccessed"
+)
+
+// Metadata returns the value of the metadata attribute from the cookie's
+// Unparsed attributes.
+func Metadata(cookie *http.Cookie, attr string) (string, bool) {
+	for _, s := range cookie.Unparsed {
+		if k, v, ok := strings.Cut(s, "="); ok && k == attr {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// addMetadata adds the Firefox metadata for the cookie to its Unparsed
+// attributes.
+func addMetadata(cookie *http.Cookie, c *models.Cookie) {
+	cookie.Unparsed = append(
+		cookie.Unparsed,
+		MetadataOriginAttributes+"="+c.OriginAttributes,
+		MetadataCreationTime+"="+strconv.FormatInt(c.CreationTime, 10),
+		MetadataLastAccessed+"="+strconv.FormatInt(c.LastAccessed, 10),
+	)
+}
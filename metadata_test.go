@@ -0,0 +1,48 @@
+package ffcookies
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWithMetadata(t *testing.T) {
+	cookies, err := ReadFile(testDB, "container.test", WithMetadata())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var attrs []string
+	for _, cookie := range cookies {
+		v, ok := Metadata(cookie, MetadataOriginAttributes)
+		if !ok {
+			t.Fatalf("expected origin attributes for %s, got: %v", cookie.Name, cookie.Unparsed)
+		}
+		attrs = append(attrs, cookie.Name+v)
+		if v, _ := Metadata(cookie, MetadataCreationTime); v != "1717200000000000" && v != "1735689600000000" {
+			t.Errorf("expected creation time for %s, got: %q", cookie.Name, v)
+		}
+		if _, ok := Metadata(cookie, MetadataLastAccessed); !ok {
+			t.Errorf("expected last accessed for %s", cookie.Name)
+		}
+	}
+	slices.Sort(attrs)
+	exp := []string{
+		"d",
+		"d^userContextId=1",
+		"k^userContextId=1",
+		"k^userContextId=2",
+		"p^partitionKey=%28https%2Cother.test%29",
+	}
+	if !slices.Equal(attrs, exp) {
+		t.Errorf("expected %v, got: %v", exp, attrs)
+	}
+	// without metadata
+	cookies, err = ReadFile(testDB, "container.test")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, cookie := range cookies {
+		if len(cookie.Unparsed) != 0 {
+			t.Errorf("expected no metadata for %s, got: %v", cookie.Name, cookie.Unparsed)
+		}
+	}
+}
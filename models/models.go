@@ -240,13 +240,23 @@ var TimestampFormats = []string{
 	"2006-01-02",
 } // Cookie is a browser cookie.
 type Cookie struct {
-	Expiry     int64  `json:"expiry"`       // expiry
-	Host       string `json:"host"`         // host
-	Name       string `json:"name"`         // name
-	Value      string `json:"value"`        // value
-	Path       string `json:"path"`         // path
-	IsSecure   bool   `json:"is_secure"`    // is_secure
-	IsHTTPOnly bool   `json:"is_http_only"` // is_http_only
+	Expiry           int64          `json:"expiry"`             // expiry
+	Host             string         `json:"host"`               // host
+	Name             string         `json:"name"`               // name
+	Value            string         `json:"value"`              // value
+	Path             string         `json:"path"`               // path
+	IsSecure         bool           `json:"is_secure"`          // is_secure
+	IsHTTPOnly       bool           `json:"is_http_only"`       // is_http_only
+	SameSite         SameSite       `json:"same_site"`          // same_site
+	RawSameSite      SameSite       `json:"raw_same_site"`      // raw_same_site
+	OriginAttributes string         `json:"origin_attributes"`  // origin_attributes
+	CreationTime     int64          `json:"creation_time"`      // creation_time
+	LastAccessed     int64          `json:"last_accessed"`      // last_accessed
+	ID               int64          `json:"id"`                 // id
+	SchemeMap        SchemeMap      `json:"scheme_map"`         // scheme_map
+	InBrowserElement bool           `json:"in_browser_element"` // in_browser_element
+	IsPartitioned    bool           `json:"is_partitioned"`     // is_partitioned
+	Extra            map[string]any `json:"extra,omitempty"`    // columns not mapped to fields
 }
 
 // Cookies retrieves cookies.
@@ -254,13 +264,22 @@ func Cookies(ctx context.Context, db DB, host string) ([]*Cookie, error) {
 	// query
 	sqlstr := `/* ` + host + ` */ ` +
 		`SELECT ` +
-		`expiry, ` +
-		`host, ` +
-		`name, ` +
-		`value, ` +
-		`path, ` +
-		`isSecure, ` +
-		`isHttpOnly ` +
+		`COALESCE(expiry, 0), ` +
+		`COALESCE(host, ''), ` +
+		`COALESCE(name, ''), ` +
+		`COALESCE(value, ''), ` +
+		`COALESCE(path, ''), ` +
+		`COALESCE(isSecure, 0), ` +
+		`COALESCE(isHttpOnly, 0), ` +
+		`COALESCE(sameSite, 0), ` +
+		`COALESCE(rawSameSite, 0), ` +
+		`COALESCE(originAttributes, ''), ` +
+		`COALESCE(creationTime, 0), ` +
+		`COALESCE(lastAccessed, 0), ` +
+		`id, ` +
+		`COALESCE(schemeMap, 0), ` +
+		`COALESCE(inBrowserElement, 0), ` +
+		`COALESCE(isPartitionedAttributeSet, 0) ` +
 		`FROM moz_cookies`
 	// run
 	logf(sqlstr)
@@ -274,7 +293,7 @@ func Cookies(ctx context.Context, db DB, host string) ([]*Cookie, error) {
 	for rows.Next() {
 		var c Cookie
 		// scan
-		if err := rows.Scan(&c.Expiry, &c.Host, &c.Name, &c.Value, &c.Path, &c.IsSecure, &c.IsHTTPOnly); err != nil {
+		if err := rows.Scan(&c.Expiry, &c.Host, &c.Name, &c.Value, &c.Path, &c.IsSecure, &c.IsHTTPOnly, &c.SameSite, &c.RawSameSite, &c.OriginAttributes, &c.CreationTime, &c.LastAccessed, &c.ID, &c.SchemeMap, &c.InBrowserElement, &c.IsPartitioned); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &c)
@@ -289,13 +308,22 @@ func Cookies(ctx context.Context, db DB, host string) ([]*Cookie, error) {
 func CookiesLikeHost(ctx context.Context, db DB, host string) ([]*Cookie, error) {
 	// query
 	const sqlstr = `SELECT ` +
-		`expiry, ` +
-		`host, ` +
-		`name, ` +
-		`value, ` +
-		`path, ` +
-		`isSecure, ` +
-		`isHttpOnly ` +
+		`COALESCE(expiry, 0), ` +
+		`COALESCE(host, ''), ` +
+		`COALESCE(name, ''), ` +
+		`COALESCE(value, ''), ` +
+		`COALESCE(path, ''), ` +
+		`COALESCE(isSecure, 0), ` +
+		`COALESCE(isHttpOnly, 0), ` +
+		`COALESCE(sameSite, 0), ` +
+		`COALESCE(rawSameSite, 0), ` +
+		`COALESCE(originAttributes, ''), ` +
+		`COALESCE(creationTime, 0), ` +
+		`COALESCE(lastAccessed, 0), ` +
+		`id, ` +
+		`COALESCE(schemeMap, 0), ` +
+		`COALESCE(inBrowserElement, 0), ` +
+		`COALESCE(isPartitionedAttributeSet, 0) ` +
 		`FROM moz_cookies ` +
 		`WHERE host LIKE $1`
 	// run
@@ -310,7 +338,7 @@ func CookiesLikeHost(ctx context.Context, db DB, host string) ([]*Cookie, error)
 	for rows.Next() {
 		var c Cookie
 		// scan
-		if err := rows.Scan(&c.Expiry, &c.Host, &c.Name, &c.Value, &c.Path, &c.IsSecure, &c.IsHTTPOnly); err != nil {
+		if err := rows.Scan(&c.Expiry, &c.Host, &c.Name, &c.Value, &c.Path, &c.IsSecure, &c.IsHTTPOnly, &c.SameSite, &c.RawSameSite, &c.OriginAttributes, &c.CreationTime, &c.LastAccessed, &c.ID, &c.SchemeMap, &c.InBrowserElement, &c.IsPartitioned); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &c)
@@ -0,0 +1,153 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// OriginAttributes are the parsed origin attributes of a Firefox cookie.
+type OriginAttributes struct {
+	UserContextID             int               `json:"user_context_id,omitempty"`
+	PrivateBrowsingID         int               `json:"private_browsing_id,omitempty"`
+	FirstPartyDomain          string            `json:"first_party_domain,omitempty"`
+	GeckoViewSessionContextID string            `json:"gecko_view_session_context_id,omitempty"`
+	PartitionKey              *PartitionKey     `json:"partition_key,omitempty"`
+	Extra                     map[string]string `json:"extra,omitempty"`
+}
+
+// ParseOriginAttributes parses a Firefox origin attributes suffix, such as
+// ^userContextId=1&partitionKey=%28https%2Cexample.com%29.
+//
+// Values are percent-decoded. Parenthesized values (as written for partition
+// keys by some Firefox versions) may contain unescaped commas and ampersands.
+func ParseOriginAttributes(s string) (OriginAttributes, error) {
+	var attrs OriginAttributes
+	s = strings.TrimPrefix(s, "^")
+	for s != "" {
+		// key
+		i := strings.IndexByte(s, '=')
+		if i <= 0 {
+			return OriginAttributes{}, ErrInvalidOriginAttributes(s)
+		}
+		key := s[:i]
+		s = s[i+1:]
+		// value
+		end := 0
+		if strings.HasPrefix(s, "(") {
+			if end = strings.IndexByte(s, ')'); end == -1 {
+				return OriginAttributes{}, ErrInvalidOriginAttributes(s)
+			}
+		}
+		if i := strings.IndexByte(s[end:], '&'); i != -1 {
+			end += i
+		} else {
+			end = len(s)
+		}
+		raw := s[:end]
+		s = strings.TrimPrefix(s[end:], "&")
+		value, err := url.PathUnescape(raw)
+		if err != nil {
+			return OriginAttributes{}, ErrInvalidOriginAttributes(raw)
+		}
+		if err := attrs.set(key, value); err != nil {
+			return OriginAttributes{}, err
+		}
+	}
+	return attrs, nil
+}
+
+// set sets the attribute key to value.
+func (attrs *OriginAttributes) set(key, value string) error {
+	var err error
+	switch key {
+	case "userContextId":
+		attrs.UserContextID, err = strconv.Atoi(value)
+	case "privateBrowsingId":
+		attrs.PrivateBrowsingID, err = strconv.Atoi(value)
+	case "firstPartyDomain":
+		attrs.FirstPartyDomain = value
+	case "geckoViewSessionContextId":
+		attrs.GeckoViewSessionContextID = value
+	case "partitionKey":
+		var key PartitionKey
+		if key, err = ParsePartitionKey(value); err == nil {
+			attrs.PartitionKey = &key
+		}
+	default:
+		if attrs.Extra == nil {
+			attrs.Extra = make(map[string]string)
+		}
+		attrs.Extra[key] = value
+	}
+	if err != nil {
+		return ErrInvalidOriginAttributes(key + "=" + value)
+	}
+	return nil
+}
+
+// PartitionKey is a parsed Firefox cookie partition key, identifying the
+// top-level site a partitioned cookie is keyed to.
+type PartitionKey struct {
+	Scheme            string `json:"scheme"`
+	Host              string `json:"host"`
+	Port              int    `json:"port,omitempty"`
+	ForeignByAncestor bool   `json:"foreign_by_ancestor,omitempty"`
+}
+
+// ParsePartitionKey parses a decoded partition key of the form
+// (scheme,host[,port][,f]).
+func ParsePartitionKey(s string) (PartitionKey, error) {
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return PartitionKey{}, ErrInvalidOriginAttributes(s)
+	}
+	fields := strings.Split(s[1:len(s)-1], ",")
+	if len(fields) < 2 || fields[0] == "" || fields[1] == "" {
+		return PartitionKey{}, ErrInvalidOriginAttributes(s)
+	}
+	key := PartitionKey{
+		Scheme: fields[0],
+		Host:   fields[1],
+	}
+	for _, field := range fields[2:] {
+		switch port, err := strconv.Atoi(field); {
+		case field == "f":
+			key.ForeignByAncestor = true
+		case err == nil && key.Port == 0:
+			key.Port = port
+		default:
+			return PartitionKey{}, ErrInvalidOriginAttributes(s)
+		}
+	}
+	return key, nil
+}
+
+// String satisfies the fmt.Stringer interface.
+func (key PartitionKey) String() string {
+	s := "(" + key.Scheme + "," + key.Host
+	if key.Port != 0 {
+		s += "," + strconv.Itoa(key.Port)
+	}
+	if key.ForeignByAncestor {
+		s += ",f"
+	}
+	return s + ")"
+}
+
+// Site returns the site of the partition key, such as https://example.com.
+func (key PartitionKey) Site() string {
+	s := key.Scheme + "://" + key.Host
+	if key.Port != 0 {
+		s += ":" + strconv.Itoa(key.Port)
+	}
+	return s
+}
+
+// ErrInvalidOriginAttributes is the invalid origin attributes error.
+type ErrInvalidOriginAttributes string
+
+// Error satisfies the error interface.
+func (err ErrInvalidOriginAttributes) Error() string {
+	return fmt.Sprintf("invalid origin attributes (%s)", string(err))
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOriginAttributes(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp OriginAttributes
+		err bool
+	}{
+		{"", OriginAttributes{}, false},
+		{"^userContextId=1", OriginAttributes{UserContextID: 1}, false},
+		{"^privateBrowsingId=1", OriginAttributes{PrivateBrowsingID: 1}, false},
+		{
+			"^partitionKey=%28https%2Cexample.com%29",
+			OriginAttributes{PartitionKey: &PartitionKey{Scheme: "https", Host: "example.com"}},
+			false,
+		},
+		{
+			"^userContextId=2&partitionKey=%28https%2Cexample.com%2C8443%29",
+			OriginAttributes{UserContextID: 2, PartitionKey: &PartitionKey{Scheme: "https", Host: "example.com", Port: 8443}},
+			false,
+		},
+		{
+			"^partitionKey=(https,example.com,f)&userContextId=3",
+			OriginAttributes{UserContextID: 3, PartitionKey: &PartitionKey{Scheme: "https", Host: "example.com", ForeignByAncestor: true}},
+			false,
+		},
+		{
+			"^firstPartyDomain=example.com&geckoViewSessionContextId=a%26b&other=x",
+			OriginAttributes{FirstPartyDomain: "example.com", GeckoViewSessionContextID: "a&b", Extra: map[string]string{"other": "x"}},
+			false,
+		},
+		{"^userContextId=a", OriginAttributes{}, true},
+		{"^partitionKey=(https,example.com", OriginAttributes{}, true},
+		{"^partitionKey=%28https%29", OriginAttributes{}, true},
+		{"^=1", OriginAttributes{}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			attrs, err := ParseOriginAttributes(test.s)
+			switch {
+			case test.err && err == nil:
+				t.Fatalf("expected error, got: %+v", attrs)
+			case !test.err && err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(attrs, test.exp) {
+				t.Errorf("expected %+v, got: %+v", test.exp, attrs)
+			}
+		})
+	}
+}
+
+func TestPartitionKey(t *testing.T) {
+	tests := []struct {
+		s    string
+		site string
+	}{
+		{"(https,example.com)", "https://example.com"},
+		{"(http,localhost,8080)", "http://localhost:8080"},
+		{"(https,example.com,f)", "https://example.com"},
+	}
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			key, err := ParsePartitionKey(test.s)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if s := key.String(); s != test.s {
+				t.Errorf("expected %q, got: %q", test.s, s)
+			}
+			if site := key.Site(); site != test.site {
+				t.Errorf("expected site %q, got: %q", test.site, site)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"context"
+	"iter"
+	"strings"
+)
+
+// CookiesWhere retrieves cookies matching the where clause. Placeholders in
+// the where clause are positional ($1, $2, ...) and are bound to args.
+func CookiesWhere(ctx context.Context, db DB, where string, args ...any) ([]*Cookie, error) {
+	var res []*Cookie
+	for c, err := range CookiesWhereSeq(ctx, db, where, args...) {
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+// CookiesWhereSeq returns an iterator over the cookies matching the where
+// clause, scanning each row as it is iterated. Iteration stops at the first
+// error. See [CookiesWhere].
+func CookiesWhereSeq(ctx context.Context, db DB, where string, args ...any) iter.Seq2[*Cookie, error] {
+	return cookiesWhereSeq(ctx, db, `moz_cookies`, nil, where, args...)
+}
+
+// cookiesWhereSeq returns an iterator over the cookies in the table matching
+// the where clause, reading the extra columns into the cookies' Extra values.
+func cookiesWhereSeq(ctx context.Context, db DB, table string, extra []string, where string, args ...any) iter.Seq2[*Cookie, error] {
+	return func(yield func(*Cookie, error) bool) {
+		// query
+		sqlstr := `SELECT ` +
+			`COALESCE(expiry, 0), ` +
+			`COALESCE(host, ''), ` +
+			`COALESCE(name, ''), ` +
+			`COALESCE(value, ''), ` +
+			`COALESCE(path, ''), ` +
+			`COALESCE(isSecure, 0), ` +
+			`COALESCE(isHttpOnly, 0), ` +
+			`COALESCE(sameSite, 0), ` +
+			`COALESCE(rawSameSite, 0), ` +
+			`COALESCE(originAttributes, ''), ` +
+			`COALESCE(creationTime, 0), ` +
+			`COALESCE(lastAccessed, 0), ` +
+			`id, ` +
+			`COALESCE(schemeMap, 0), ` +
+			`COALESCE(inBrowserElement, 0), ` +
+			`COALESCE(isPartitionedAttributeSet, 0)`
+		for _, name := range extra {
+			sqlstr += `, "` + strings.ReplaceAll(name, `"`, `""`) + `"`
+		}
+		sqlstr += ` FROM ` + table
+		if where != "" {
+			sqlstr += ` WHERE ` + where
+		}
+		// run
+		logf(sqlstr, args...)
+		rows, err := db.QueryContext(ctx, sqlstr, args...)
+		if err != nil {
+			yield(nil, logerror(err))
+			return
+		}
+		defer rows.Close()
+		// load results
+		for rows.Next() {
+			var c Cookie
+			dest := []any{&c.Expiry, &c.Host, &c.Name, &c.Value, &c.Path, &c.IsSecure, &c.IsHTTPOnly, &c.SameSite, &c.RawSameSite, &c.OriginAttributes, &c.CreationTime, &c.LastAccessed, &c.ID, &c.SchemeMap, &c.InBrowserElement, &c.IsPartitioned}
+			values := make([]any, len(extra))
+			for i := range values {
+				dest = append(dest, &values[i])
+			}
+			// scan
+			if err := rows.Scan(dest...); err != nil {
+				yield(nil, logerror(err))
+				return
+			}
+			for i, name := range extra {
+				if c.Extra == nil {
+					c.Extra = make(map[string]any, len(extra))
+				}
+				c.Extra[name] = values[i]
+			}
+			if !yield(&c, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(nil, logerror(err))
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SameSite is a Firefox cookie SameSite value, as stored in the sameSite and
+// rawSameSite columns.
+type SameSite int
+
+// SameSite values (see nsICookie.idl).
+const (
+	SameSiteNone   SameSite = 0
+	SameSiteLax    SameSite = 1
+	SameSiteStrict SameSite = 2
+	SameSiteUnset  SameSite = 256
+)
+
+// ParseSameSite parses a SameSite value (case insensitive).
+func ParseSameSite(s string) (SameSite, error) {
+	switch strings.ToLower(s) {
+	case "none":
+		return SameSiteNone, nil
+	case "lax":
+		return SameSiteLax, nil
+	case "strict":
+		return SameSiteStrict, nil
+	case "unset", "":
+		return SameSiteUnset, nil
+	}
+	return 0, ErrInvalidSameSite(s)
+}
+
+// SameSiteFromHTTP converts a http.SameSite to a SameSite.
+func SameSiteFromHTTP(sameSite http.SameSite) SameSite {
+	switch sameSite {
+	case http.SameSiteNoneMode:
+		return SameSiteNone
+	case http.SameSiteLaxMode:
+		return SameSiteLax
+	case http.SameSiteStrictMode:
+		return SameSiteStrict
+	}
+	return SameSiteUnset
+}
+
+// String satisfies the fmt.Stringer interface.
+func (sameSite SameSite) String() string {
+	switch sameSite {
+	case SameSiteNone:
+		return "None"
+	case SameSiteLax:
+		return "Lax"
+	case SameSiteStrict:
+		return "Strict"
+	case SameSiteUnset:
+		return "Unset"
+	}
+	return fmt.Sprintf("SameSite(%d)", int(sameSite))
+}
+
+// HTTP returns the http.SameSite for the value: [http.SameSiteNoneMode],
+// [http.SameSiteLaxMode] or [http.SameSiteStrictMode], or
+// [http.SameSiteDefaultMode] for [SameSiteUnset] and unknown values. See
+// [Cookie.EffectiveSameSite] for the value Firefox enforces for a cookie.
+func (sameSite SameSite) HTTP() http.SameSite {
+	switch sameSite {
+	case SameSiteNone:
+		return http.SameSiteNoneMode
+	case SameSiteLax:
+		return http.SameSiteLaxMode
+	case SameSiteStrict:
+		return http.SameSiteStrictMode
+	}
+	return http.SameSiteDefaultMode
+}
+
+// MarshalText satisfies the [encoding.TextMarshaler] interface.
+func (sameSite SameSite) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(sameSite.String())), nil
+}
+
+// UnmarshalText satisfies the [encoding.TextUnmarshaler] interface.
+func (sameSite *SameSite) UnmarshalText(text []byte) error {
+	var err error
+	*sameSite, err = ParseSameSite(string(text))
+	return err
+}
+
+// ErrInvalidSameSite is the invalid SameSite error.
+type ErrInvalidSameSite string
+
+// Error satisfies the error interface.
+func (err ErrInvalidSameSite) Error() string {
+	return fmt.Sprintf("invalid SameSite (%s)", string(err))
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSameSite(t *testing.T) {
+	tests := []struct {
+		sameSite SameSite
+		exp      string
+		h        http.SameSite
+	}{
+		{SameSiteNone, "None", http.SameSiteNoneMode},
+		{SameSiteLax, "Lax", http.SameSiteLaxMode},
+		{SameSiteStrict, "Strict", http.SameSiteStrictMode},
+		{SameSiteUnset, "Unset", http.SameSiteDefaultMode},
+	}
+	for _, test := range tests {
+		t.Run(test.exp, func(t *testing.T) {
+			if s := test.sameSite.String(); s != test.exp {
+				t.Errorf("expected %q, got: %q", test.exp, s)
+			}
+			sameSite, err := ParseSameSite(test.exp)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if sameSite != test.sameSite {
+				t.Errorf("expected %d, got: %d", test.sameSite, sameSite)
+			}
+			if h := test.sameSite.HTTP(); h != test.h {
+				t.Errorf("expected http %d, got: %d", test.h, h)
+			}
+			if sameSite := SameSiteFromHTTP(test.h); sameSite != test.sameSite {
+				t.Errorf("expected %d from http, got: %d", test.sameSite, sameSite)
+			}
+			text, err := test.sameSite.MarshalText()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if err := sameSite.UnmarshalText(text); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if sameSite != test.sameSite {
+				t.Errorf("expected %d from text, got: %d", test.sameSite, sameSite)
+			}
+		})
+	}
+}
+
+func TestParseSameSite(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp SameSite
+		err bool
+	}{
+		{"lax", SameSiteLax, false},
+		{"STRICT", SameSiteStrict, false},
+		{"", SameSiteUnset, false},
+		{"bogus", 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			sameSite, err := ParseSameSite(test.s)
+			switch {
+			case test.err && err == nil:
+				t.Fatalf("expected error")
+			case !test.err && err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if sameSite != test.exp {
+				t.Errorf("expected %d, got: %d", test.exp, sameSite)
+			}
+		})
+	}
+	if s, exp := SameSite(3).String(), "SameSite(3)"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// Schema is the schema of a Firefox cookie database.
+type Schema struct {
+	// Version is the schema version, as stored in the database's
+	// user_version.
+	Version int `json:"version"`
+	// Columns are the moz_cookies table's column names.
+	Columns []string `json:"columns"`
+}
+
+// columns are the moz_cookies columns mapped to the fields of a [Cookie], and
+// the default values used when a column is not present, as in the schemas of
+// older Firefox versions (and some forks).
+var columns = []struct {
+	name  string
+	value string
+}{
+	{"expiry", "0"},
+	{"host", "''"},
+	{"name", "''"},
+	{"value", "''"},
+	{"path", "''"},
+	{"isSecure", "0"},
+	{"isHttpOnly", "0"},
+	{"sameSite", "0"},
+	{"rawSameSite", "0"},
+	{"originAttributes", "''"},
+	{"creationTime", "0"},
+	{"lastAccessed", "0"},
+	{"id", "rowid"},
+	{"schemeMap", "0"},
+	{"inBrowserElement", "0"},
+	{"isPartitionedAttributeSet", "0"},
+}
+
+// ReadSchema reads the schema of the cookie database.
+func ReadSchema(ctx context.Context, db DB) (*Schema, error) {
+	// query
+	const sqlstr = `PRAGMA user_version`
+	// run
+	logf(sqlstr)
+	var s Schema
+	if err := db.QueryRowContext(ctx, sqlstr).Scan(&s.Version); err != nil {
+		return nil, logerror(err)
+	}
+	// query
+	const colstr = `SELECT name FROM pragma_table_info('moz_cookies')`
+	// run
+	logf(colstr)
+	rows, err := db.QueryContext(ctx, colstr)
+	if err != nil {
+		return nil, logerror(err)
+	}
+	defer rows.Close()
+	// load results
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, logerror(err)
+		}
+		s.Columns = append(s.Columns, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, logerror(err)
+	}
+	return &s, nil
+}
+
+// Has returns true when the schema has the named column.
+func (s *Schema) Has(name string) bool {
+	for _, col := range s.Columns {
+		if strings.EqualFold(col, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Extra returns the schema's columns not mapped to the fields of a [Cookie],
+// such as the columns of legacy schemas or columns added by newer Firefox
+// versions. The values of extra columns are read into [Cookie.Extra].
+func (s *Schema) Extra() []string {
+	var extra []string
+	for _, col := range s.Columns {
+		if !slices.ContainsFunc(columns, func(c struct{ name, value string }) bool {
+			return strings.EqualFold(c.name, col)
+		}) {
+			extra = append(extra, col)
+		}
+	}
+	return extra
+}
+
+// Missing returns the columns mapped to the fields of a [Cookie] that are not
+// present in the schema. Missing columns are read as their default values.
+func (s *Schema) Missing() []string {
+	var missing []string
+	for _, col := range columns {
+		if !s.Has(col.name) {
+			missing = append(missing, col.name)
+		}
+	}
+	return missing
+}
+
+// Legacy returns true when the schema is missing columns present in current
+// Firefox versions.
+func (s *Schema) Legacy() bool {
+	return len(s.Missing()) != 0
+}
+
+// table returns the table to select cookies from. For legacy schemas, the
+// moz_cookies table is wrapped in a subquery supplying the defaults for the
+// missing columns, so that queries can use the columns of current schemas.
+func (s *Schema) table() string {
+	var cols []string
+	for _, col := range columns {
+		if !s.Has(col.name) {
+			cols = append(cols, col.value+` AS `+col.name)
+		}
+	}
+	if len(cols) == 0 {
+		return `moz_cookies`
+	}
+	return `(SELECT *, ` + strings.Join(cols, `, `) + ` FROM moz_cookies) AS moz_cookies`
+}
+
+// CookiesWhereSeq returns an iterator over the cookies matching the where
+// clause, adapting the query to the schema. See [CookiesWhereSeq].
+func (s *Schema) CookiesWhereSeq(ctx context.Context, db DB, where string, args ...any) iter.Seq2[*Cookie, error] {
+	return cookiesWhereSeq(ctx, db, s.table(), s.Extra(), where, args...)
+}
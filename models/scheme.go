@@ -0,0 +1,50 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SchemeMap is a Firefox cookie scheme map, as stored in the schemeMap
+// column. The scheme map is a bit set of the schemes the cookie was set or
+// sent over.
+type SchemeMap int
+
+// SchemeMap values (see nsICookie.idl).
+const (
+	SchemeUnset SchemeMap = 0x00
+	SchemeHTTP  SchemeMap = 0x01
+	SchemeHTTPS SchemeMap = 0x02
+	SchemeFile  SchemeMap = 0x04
+)
+
+// Has returns true when the scheme map includes all of the schemes in
+// scheme.
+func (schemeMap SchemeMap) Has(scheme SchemeMap) bool {
+	return schemeMap&scheme == scheme
+}
+
+// String satisfies the fmt.Stringer interface.
+func (schemeMap SchemeMap) String() string {
+	if schemeMap == SchemeUnset {
+		return "Unset"
+	}
+	var s []string
+	for _, v := range []struct {
+		scheme SchemeMap
+		name   string
+	}{
+		{SchemeHTTP, "HTTP"},
+		{SchemeHTTPS, "HTTPS"},
+		{SchemeFile, "File"},
+	} {
+		if schemeMap.Has(v.scheme) {
+			s = append(s, v.name)
+			schemeMap &^= v.scheme
+		}
+	}
+	if schemeMap != 0 {
+		s = append(s, fmt.Sprintf("SchemeMap(%#x)", int(schemeMap)))
+	}
+	return strings.Join(s, "|")
+}
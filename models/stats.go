@@ -0,0 +1,119 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Stats are aggregate cookie statistics.
+type Stats struct {
+	Total          int              `json:"total"`
+	Secure         int              `json:"secure"`
+	HTTPOnly       int              `json:"http_only"`
+	Session        int              `json:"session"`
+	Expired        int              `json:"expired"`
+	Domains        int              `json:"domains"`
+	SameSite       map[SameSite]int `json:"same_site"`
+	OldestCreation time.Time        `json:"oldest_creation"`
+	NewestCreation time.Time        `json:"newest_creation"`
+}
+
+// CookieStats retrieves aggregate cookie statistics in a single pass. Cookies
+// with an expiry at or before now (in seconds since the epoch) are counted as
+// expired.
+func CookieStats(ctx context.Context, db DB, now int64) (*Stats, error) {
+	return cookieStats(ctx, db, `moz_cookies`, now)
+}
+
+// CookieStats retrieves aggregate cookie statistics, adapting the query to the
+// schema. See [CookieStats].
+func (s *Schema) CookieStats(ctx context.Context, db DB, now int64) (*Stats, error) {
+	return cookieStats(ctx, db, s.table(), now)
+}
+
+// cookieStats retrieves aggregate statistics for the cookies in the table.
+func cookieStats(ctx context.Context, db DB, table string, now int64) (*Stats, error) {
+	// query
+	sqlstr := `SELECT ` +
+		`COUNT(*), ` +
+		`COALESCE(SUM(isSecure <> 0), 0), ` +
+		`COALESCE(SUM(isHttpOnly <> 0), 0), ` +
+		`COALESCE(SUM(expiry = 0), 0), ` +
+		`COALESCE(SUM(expiry <> 0 AND expiry <= $1), 0), ` +
+		`COUNT(DISTINCT host), ` +
+		`COALESCE(SUM(sameSite = 0), 0), ` +
+		`COALESCE(SUM(sameSite = 1), 0), ` +
+		`COALESCE(SUM(sameSite = 2), 0), ` +
+		`COALESCE(SUM(sameSite = 256), 0), ` +
+		`COALESCE(MIN(creationTime), 0), ` +
+		`COALESCE(MAX(creationTime), 0) ` +
+		`FROM ` + table
+	// run
+	logf(sqlstr, now)
+	var s Stats
+	var none, lax, strict, unset int
+	var oldest, newest int64
+	if err := db.QueryRowContext(ctx, sqlstr, now).Scan(
+		&s.Total, &s.Secure, &s.HTTPOnly, &s.Session, &s.Expired, &s.Domains,
+		&none, &lax, &strict, &unset, &oldest, &newest,
+	); err != nil {
+		return nil, logerror(err)
+	}
+	s.SameSite = map[SameSite]int{
+		SameSiteNone:   none,
+		SameSiteLax:    lax,
+		SameSiteStrict: strict,
+		SameSiteUnset:  unset,
+	}
+	// creationTime is stored in microseconds since the epoch
+	if s.Total != 0 {
+		s.OldestCreation, s.NewestCreation = time.UnixMicro(oldest), time.UnixMicro(newest)
+	}
+	return &s, nil
+}
+
+// StatsOf returns aggregate statistics for the cookies, computed as with
+// [CookieStats].
+func StatsOf(cookies []*Cookie, now int64) *Stats {
+	s := &Stats{
+		SameSite: map[SameSite]int{
+			SameSiteNone:   0,
+			SameSiteLax:    0,
+			SameSiteStrict: 0,
+			SameSiteUnset:  0,
+		},
+	}
+	hosts := make(map[string]bool)
+	var oldest, newest int64
+	for i, c := range cookies {
+		s.Total++
+		if c.IsSecure {
+			s.Secure++
+		}
+		if c.IsHTTPOnly {
+			s.HTTPOnly++
+		}
+		switch {
+		case c.Expiry == 0:
+			s.Session++
+		case c.Expiry <= now:
+			s.Expired++
+		}
+		hosts[c.Host] = true
+		if _, ok := s.SameSite[c.SameSite]; ok {
+			s.SameSite[c.SameSite]++
+		}
+		if i == 0 || c.CreationTime < oldest {
+			oldest = c.CreationTime
+		}
+		if i == 0 || c.CreationTime > newest {
+			newest = c.CreationTime
+		}
+	}
+	s.Domains = len(hosts)
+	// creationTime is stored in microseconds since the epoch
+	if s.Total != 0 {
+		s.OldestCreation, s.NewestCreation = time.UnixMicro(oldest), time.UnixMicro(newest)
+	}
+	return s
+}
@@ -2,23 +2,127 @@ package models
 
 import (
 	"net/http"
+	"strings"
 	"time"
+
+	"golang.org/x/net/idna"
 )
 
-// Convert converts a slice of Cookie to http.Cookie.
+// Convert converts a slice of Cookie to http.Cookie. Cookies with empty names
+// are skipped. See [ConvertOne].
 func Convert(res []*Cookie) []*http.Cookie {
-	var cookies []*http.Cookie
+	return ConvertDefault(res, SameSiteLax)
+}
+
+// ConvertDefault converts a slice of Cookie to http.Cookie, using def as the
+// SameSite value for cookies where it is unspecified. Cookies with empty names
+// are skipped. See [Cookie.EffectiveSameSite].
+func ConvertDefault(res []*Cookie, def SameSite) []*http.Cookie {
+	cookies := make([]*http.Cookie, 0, len(res))
 	for _, c := range res {
-		cookies = append(cookies, &http.Cookie{
-			Name:     c.Name,
-			Value:    c.Value,
-			Path:     c.Path,
-			Domain:   c.Host,
-			Expires:  time.Unix(c.Expiry, 0),
-			Secure:   c.IsSecure,
-			HttpOnly: c.IsHTTPOnly,
-			// SameSite: c.SameSite,
-		})
+		if cookie := ConvertOneDefault(c, def); cookie != nil {
+			cookies = append(cookies, cookie)
+		}
 	}
 	return cookies
 }
+
+// ConvertOne converts a Cookie to a http.Cookie. Returns nil when the cookie
+// has an empty name, as it is not a valid http.Cookie.
+//
+// Cookies where the SameSite value is unspecified are treated as
+// SameSite=Lax, matching the default of current Firefox versions.
+func ConvertOne(c *Cookie) *http.Cookie {
+	return ConvertOneDefault(c, SameSiteLax)
+}
+
+// ConvertOneDefault converts a Cookie to a http.Cookie, using def as the
+// SameSite value when it is unspecified. Returns nil when the cookie has an
+// empty name. Passing [SameSiteUnset] leaves the http.Cookie's SameSite as
+// [http.SameSiteDefaultMode], emulating older Firefox versions.
+func ConvertOneDefault(c *Cookie, def SameSite) *http.Cookie {
+	if c.Name == "" {
+		return nil
+	}
+	value, quoted := unquote(c.Value)
+	cookie := &http.Cookie{
+		Name:        c.Name,
+		Value:       value,
+		Quoted:      quoted,
+		Path:        c.Path,
+		Domain:      c.Host,
+		Secure:      c.IsSecure,
+		HttpOnly:    c.IsHTTPOnly,
+		SameSite:    c.EffectiveSameSite(def).HTTP(),
+		Partitioned: c.IsPartitioned,
+	}
+	// session cookies are stored with an expiry of 0, and are left without
+	// an expiry
+	if !c.Session() {
+		cookie.Expires = time.Unix(c.Expiry, 0)
+	}
+	return cookie
+}
+
+// Session returns true when the cookie is a session cookie, which Firefox
+// stores with an expiry of 0.
+func (c *Cookie) Session() bool {
+	return c.Expiry == 0
+}
+
+// EffectiveSameSite returns the SameSite value Firefox enforces for the
+// cookie: the sameSite value when set, otherwise the rawSameSite value (as
+// sent by the server) when set, otherwise def.
+func (c *Cookie) EffectiveSameSite(def SameSite) SameSite {
+	switch {
+	case c.SameSite != SameSiteUnset:
+		return c.SameSite
+	case c.RawSameSite != SameSiteUnset:
+		return c.RawSameSite
+	}
+	return def
+}
+
+// HostOnly returns true when the cookie is a host-only cookie, which Firefox
+// stores without a leading dot on the host. Host-only cookies are not sent to
+// subdomains of the host.
+func (c *Cookie) HostOnly() bool {
+	return !strings.HasPrefix(c.Host, ".")
+}
+
+// UnicodeHost returns the cookie's host with internationalized domain names
+// decoded from their ASCII (punycode) form, as stored by Firefox. Returns the
+// host unchanged when it cannot be decoded.
+func (c *Cookie) UnicodeHost() string {
+	if s, err := idna.ToUnicode(c.Host); err == nil {
+		return s
+	}
+	return c.Host
+}
+
+// Created returns the cookie's creation time. Firefox stores the creation
+// time in microseconds since the epoch.
+func (c *Cookie) Created() time.Time {
+	return time.UnixMicro(c.CreationTime)
+}
+
+// Accessed returns the cookie's last accessed time. Firefox stores the last
+// accessed time in microseconds since the epoch.
+func (c *Cookie) Accessed() time.Time {
+	return time.UnixMicro(c.LastAccessed)
+}
+
+// Origin returns the parsed origin attributes of the cookie.
+func (c *Cookie) Origin() (OriginAttributes, error) {
+	return ParseOriginAttributes(c.OriginAttributes)
+}
+
+// unquote removes the surrounding double quotes from a cookie value, if
+// present. net/http re-adds the quotes when the cookie is marked as quoted, so
+// the value is sent exactly as stored by Firefox.
+func unquote(value string) (string, bool) {
+	if len(value) > 1 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1], true
+	}
+	return value, false
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestHostOnly(t *testing.T) {
+	tests := []struct {
+		host string
+		exp  bool
+	}{
+		{"example.com", true},
+		{".example.com", false},
+		{"127.0.0.1", true},
+	}
+	for _, test := range tests {
+		t.Run(test.host, func(t *testing.T) {
+			if hostOnly := (&Cookie{Host: test.host}).HostOnly(); hostOnly != test.exp {
+				t.Errorf("expected %t, got: %t", test.exp, hostOnly)
+			}
+		})
+	}
+}
+
+func TestEffectiveSameSite(t *testing.T) {
+	tests := []struct {
+		name        string
+		sameSite    SameSite
+		rawSameSite SameSite
+		def         SameSite
+		exp         SameSite
+		h           http.SameSite
+	}{
+		{"sameSite", SameSiteStrict, SameSiteNone, SameSiteLax, SameSiteStrict, http.SameSiteStrictMode},
+		{"sameSite none", SameSiteNone, SameSiteStrict, SameSiteLax, SameSiteNone, http.SameSiteNoneMode},
+		{"rawSameSite", SameSiteUnset, SameSiteStrict, SameSiteLax, SameSiteStrict, http.SameSiteStrictMode},
+		{"default", SameSiteUnset, SameSiteUnset, SameSiteLax, SameSiteLax, http.SameSiteLaxMode},
+		{"default unset", SameSiteUnset, SameSiteUnset, SameSiteUnset, SameSiteUnset, http.SameSiteDefaultMode},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Cookie{
+				Name:        "a",
+				Host:        ".example.com",
+				Path:        "/",
+				SameSite:    test.sameSite,
+				RawSameSite: test.rawSameSite,
+			}
+			if sameSite := c.EffectiveSameSite(test.def); sameSite != test.exp {
+				t.Errorf("expected %v, got: %v", test.exp, sameSite)
+			}
+			if h := ConvertOneDefault(c, test.def).SameSite; h != test.h {
+				t.Errorf("expected http %d, got: %d", test.h, h)
+			}
+		})
+	}
+}
+
+func BenchmarkConvert(b *testing.B) {
+	// about 10k cookies, as in a long used profile
+	res := make([]*Cookie, 10000)
+	for i := range res {
+		res[i] = &Cookie{
+			ID:           int64(i + 1),
+			Name:         "cookie" + strconv.Itoa(i%50),
+			Value:        strconv.Quote(strconv.Itoa(i)),
+			Host:         ".host" + strconv.Itoa(i/50) + ".example.com",
+			Path:         "/",
+			Expiry:       int64(1735689600 + i*(i%2)),
+			LastAccessed: int64(1735689600000000 + i),
+			CreationTime: int64(1735689600000000 + i),
+			IsSecure:     i%3 == 0,
+			IsHTTPOnly:   i%5 == 0,
+			SameSite:     SameSite(i % 3),
+		}
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if cookies := Convert(res); len(cookies) != len(res) {
+			b.Fatalf("expected %d cookies, got: %d", len(res), len(cookies))
+		}
+	}
+}
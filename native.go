@@ -0,0 +1,154 @@
+package ffcookies
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"strconv"
+
+	"github.com/kenshaw/ffcookies/internal/sqlite"
+	"github.com/kenshaw/ffcookies/models"
+)
+
+// source is a source of cookies.
+type source interface {
+	// cookies returns an iterator over the cookies matching the query.
+	cookies(context.Context, *query) iter.Seq2[*models.Cookie, error]
+}
+
+// collect collects the cookies from an iterator, returning the first error.
+func collect(seq iter.Seq2[*models.Cookie, error]) ([]*models.Cookie, error) {
+	var res []*models.Cookie
+	for c, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+// dbSource is a cookie source for a database opened with a sqlite3 driver.
+type dbSource struct {
+	db models.DB
+}
+
+// cookies satisfies the source interface.
+func (src dbSource) cookies(ctx context.Context, q *query) iter.Seq2[*models.Cookie, error] {
+	return func(yield func(*models.Cookie, error) bool) {
+		// adapt the query to legacy schemas
+		schema, err := models.ReadSchema(ctx, src.db)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for c, err := range schema.CookiesWhereSeq(ctx, src.db, q.where(), q.args...) {
+			if !yield(c, err) {
+				return
+			}
+		}
+	}
+}
+
+// nativeSource is a cookie source for a database read without a sqlite3
+// driver. The query is evaluated using its matching funcs.
+type nativeSource struct {
+	db *sqlite.DB
+}
+
+// errStop is used to stop scanning a table.
+var errStop = errors.New("stop")
+
+// cookies satisfies the source interface.
+func (src nativeSource) cookies(ctx context.Context, q *query) iter.Seq2[*models.Cookie, error] {
+	return func(yield func(*models.Cookie, error) bool) {
+		t, err := src.db.Table("moz_cookies")
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		cols := make(map[string]int)
+		for i, name := range t.Columns {
+			cols[name] = i
+		}
+		extra := (&models.Schema{Columns: t.Columns}).Extra()
+		err = t.Scan(func(rowid int64, v []any) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			c := &models.Cookie{
+				Expiry:           nativeInt(v, cols, "expiry"),
+				Host:             nativeString(v, cols, "host"),
+				Name:             nativeString(v, cols, "name"),
+				Value:            nativeString(v, cols, "value"),
+				Path:             nativeString(v, cols, "path"),
+				IsSecure:         nativeInt(v, cols, "isSecure") != 0,
+				IsHTTPOnly:       nativeInt(v, cols, "isHttpOnly") != 0,
+				SameSite:         models.SameSite(nativeInt(v, cols, "sameSite")),
+				RawSameSite:      models.SameSite(nativeInt(v, cols, "rawSameSite")),
+				OriginAttributes: nativeString(v, cols, "originAttributes"),
+				CreationTime:     nativeInt(v, cols, "creationTime"),
+				LastAccessed:     nativeInt(v, cols, "lastAccessed"),
+				ID:               nativeInt(v, cols, "id"),
+				SchemeMap:        models.SchemeMap(nativeInt(v, cols, "schemeMap")),
+				InBrowserElement: nativeInt(v, cols, "inBrowserElement") != 0,
+				IsPartitioned:    nativeInt(v, cols, "isPartitionedAttributeSet") != 0,
+			}
+			for _, name := range extra {
+				if c.Extra == nil {
+					c.Extra = make(map[string]any, len(extra))
+				}
+				c.Extra[name] = v[cols[name]]
+			}
+			if _, ok := cols["id"]; !ok {
+				c.ID = rowid
+			}
+			if q.match(c) && !yield(c, nil) {
+				return errStop
+			}
+			return nil
+		})
+		if err != nil && err != errStop {
+			yield(nil, err)
+		}
+	}
+}
+
+// nativeInt returns the integer value of the named column, or 0 when the
+// column does not exist or is NULL.
+func nativeInt(v []any, cols map[string]int, name string) int64 {
+	i, ok := cols[name]
+	if !ok {
+		return 0
+	}
+	switch x := v[i].(type) {
+	case int64:
+		return x
+	case float64:
+		return int64(x)
+	case string:
+		n, _ := strconv.ParseInt(x, 10, 64)
+		return n
+	}
+	return 0
+}
+
+// nativeString returns the string value of the named column, or the empty
+// string when the column does not exist or is NULL.
+func nativeString(v []any, cols map[string]int, name string) string {
+	i, ok := cols[name]
+	if !ok {
+		return ""
+	}
+	switch x := v[i].(type) {
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package ffcookies
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kenshaw/ffcookies/models"
+)
+
+// NetscapeHeader is the header written to Netscape cookie files.
+const NetscapeHeader = "# Netscape HTTP Cookie File"
+
+// WriteNetscape writes the cookies to w in the Netscape cookie file format, as
+// used by curl, wget and yt-dlp. HttpOnly cookies have their domain prefixed
+// with #HttpOnly_, following curl's convention.
+func WriteNetscape(w io.Writer, cookies ...*http.Cookie) error {
+	return writeNetscape(w, false, cookies)
+}
+
+// WriteNetscapeExtended writes the cookies to w in the Netscape cookie file
+// format, with the cookies' creation and last accessed times (in microseconds
+// since the epoch, or 0 when not known) as two additional trailing columns.
+// The times are taken from the cookies' metadata (see [WithMetadata]).
+//
+// Standard consumers ignore the additional columns.
+func WriteNetscapeExtended(w io.Writer, cookies ...*http.Cookie) error {
+	return writeNetscape(w, true, cookies)
+}
+
+// writeNetscape writes the cookies to w in the Netscape cookie file format.
+func writeNetscape(w io.Writer, extended bool, cookies []*http.Cookie) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, NetscapeHeader)
+	for _, cookie := range cookies {
+		domain := cookie.Domain
+		if cookie.HttpOnly {
+			domain = "#HttpOnly_" + domain
+		}
+		var expiry int64
+		if !cookie.Expires.IsZero() && cookie.Expires.Unix() > 0 {
+			expiry = cookie.Expires.Unix()
+		}
+		value := cookie.Value
+		if cookie.Quoted {
+			value = `"` + value + `"`
+		}
+		fmt.Fprintf(
+			bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s",
+			domain, netscapeBool(strings.HasPrefix(cookie.Domain, ".")),
+			cookie.Path, netscapeBool(cookie.Secure),
+			expiry, cookie.Name, value,
+		)
+		if extended {
+			fmt.Fprintf(bw, "\t%s\t%s", metadataInt(cookie, MetadataCreationTime), metadataInt(cookie, MetadataLastAccessed))
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// ReadNetscape reads cookies in the Netscape cookie file format from r.
+// Fields after the 7th on a line are ignored.
+func ReadNetscape(r io.Reader) ([]*http.Cookie, error) {
+	var cookies []*http.Cookie
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for i := 1; s.Scan(); i++ {
+		line := strings.TrimRight(s.Text(), "\r")
+		httpOnly := strings.HasPrefix(line, "#HttpOnly_")
+		if httpOnly {
+			line = strings.TrimPrefix(line, "#HttpOnly_")
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("line %d: expected 7 fields, got %d", i, len(fields))
+		}
+		expiry, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiry %q", i, fields[4])
+		}
+		host := fields[0]
+		if strings.EqualFold(fields[1], "TRUE") && !strings.HasPrefix(host, ".") {
+			host = "." + host
+		}
+		cookie := models.ConvertOneDefault(&models.Cookie{
+			Expiry:      expiry,
+			Host:        host,
+			Name:        fields[5],
+			Value:       fields[6],
+			Path:        fields[2],
+			IsSecure:    strings.EqualFold(fields[3], "TRUE"),
+			IsHTTPOnly:  httpOnly,
+			SameSite:    models.SameSiteUnset,
+			RawSameSite: models.SameSiteUnset,
+		}, models.SameSiteUnset)
+		if cookie != nil {
+			cookies = append(cookies, cookie)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return cookies, nil
+}
+
+// ExportAllNetscape reads all cookies for the provided Firefox profile name (or
+// the default Firefox profile) and writes them to w in the Netscape cookie
+// file format. Expired cookies are not written unless [WithIncludeExpired] is
+// passed. Use [WithNetscapeExtended] to write the extended format.
+func ExportAllNetscape(ctx context.Context, profile string, w io.Writer, opts ...Option) error {
+	cookies, err := ReadContext(ctx, profile, "", opts...)
+	if err != nil {
+		return err
+	}
+	return writeNetscape(w, newOptions(opts...).netscapeExtended, cookies)
+}
+
+// netscapeBool returns the Netscape cookie file format representation of b.
+func netscapeBool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// metadataInt returns the integer metadata attribute of the cookie, or 0 when
+// not present.
+func metadataInt(cookie *http.Cookie, attr string) string {
+	if v, ok := Metadata(cookie, attr); ok {
+		if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return v
+		}
+	}
+	return "0"
+}
@@ -0,0 +1,102 @@
+package ffcookies
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExportAllNetscape(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		exp  []string
+	}{
+		{"default", nil, []string{"httponly", "live", "session"}},
+		{"include expired", []Option{WithIncludeExpired(true)}, []string{"expired", "httponly", "live", "session"}},
+		{"extended", []Option{WithNetscapeExtended(), WithMetadata()}, []string{"httponly", "live", "session"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := append([]Option{WithCookieFile(testDB), WithHosts("export.test")}, test.opts...)
+			if err := ExportAllNetscape(context.Background(), "", &buf, opts...); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !strings.HasPrefix(buf.String(), NetscapeHeader+"\n") {
+				t.Errorf("expected header, got: %q", buf.String())
+			}
+			cookies, err := ReadNetscape(&buf)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if names := cookieNames(cookies); !slices.Equal(names, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, names)
+			}
+			for _, cookie := range cookies {
+				switch cookie.Name {
+				case "httponly":
+					if !cookie.HttpOnly || cookie.Domain != ".export.test" {
+						t.Errorf("expected http only cookie for .export.test, got: %v", cookie)
+					}
+				case "session":
+					if !cookie.Expires.IsZero() || cookie.Secure || cookie.Domain != "www.export.test" || cookie.Path != "/a" {
+						t.Errorf("expected insecure session cookie for www.export.test/a, got: %v", cookie)
+					}
+				case "live":
+					if cookie.Expires.Unix() != 4102444800 || !cookie.Secure || cookie.Value != "1" {
+						t.Errorf("expected secure cookie expiring at 4102444800, got: %v", cookie)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWriteNetscapeExtended(t *testing.T) {
+	cookies, err := ReadFile(testDB, "export.test", WithMetadata())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var std, ext bytes.Buffer
+	if err := WriteNetscape(&std, cookies...); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := WriteNetscapeExtended(&ext, cookies...); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, test := range []struct {
+		s      string
+		fields int
+	}{
+		{std.String(), 7},
+		{ext.String(), 9},
+	} {
+		lines := strings.Split(strings.TrimSuffix(test.s, "\n"), "\n")[1:]
+		if len(lines) != len(cookies) {
+			t.Fatalf("expected %d lines, got: %d", len(cookies), len(lines))
+		}
+		for _, line := range lines {
+			if n := len(strings.Split(line, "\t")); n != test.fields {
+				t.Errorf("expected %d fields, got %d: %q", test.fields, n, line)
+			}
+		}
+	}
+	if !strings.Contains(ext.String(), "\t1735689600000000\t1735689600000000\n") {
+		t.Errorf("expected creation and last accessed times, got: %q", ext.String())
+	}
+	a, err := ReadNetscape(&std)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	b, err := ReadNetscape(&ext)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expected extended format to read as standard format, got: %v and %v", a, b)
+	}
+}
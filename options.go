@@ -0,0 +1,1015 @@
+package ffcookies
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kenshaw/ffcookies/internal/sqlite"
+	"github.com/kenshaw/ffcookies/models"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Option is a read option.
+type Option func(*options)
+
+// options are read options.
+type options struct {
+	// profile and host are the profile name and host to read
+	profile string
+	host    string
+	// profileDir is the base profile directory
+	profileDir string
+	// browser is the browser whose base profile directory is used
+	browser Browser
+	// searchPaths are the base profile directories searched before (or,
+	// when replaceSearchPaths is set, instead of) the browser's directories
+	searchPaths        []string
+	replaceSearchPaths bool
+	// channel is the channel of the default profile
+	channel Channel
+	// source is the cookie source
+	source CookieSource
+	// concurrency is the maximum number of concurrent reads
+	concurrency int
+	// usersDir is the directory containing the users' home directories
+	usersDir string
+	// noEnv ignores the EnvProfileDir and EnvProfile environment variables
+	noEnv bool
+	// driver is the sqlite3 driver name
+	driver string
+	// openParams are the sqlite3 open parameters
+	openParams string
+	// live reads the live state of the database, including its write-ahead
+	// log
+	live bool
+	// snapshot reads from a snapshot of the database
+	snapshot bool
+	// retryPolicy is the policy for retrying busy reads
+	retryPolicy RetryPolicy
+	// lockStrategy is the strategy for reading locked databases
+	lockStrategy LockStrategy
+	// profilePath is the profile directory, bypassing profile resolution
+	profilePath string
+	// cookieFile is the cookie database path, bypassing profile resolution
+	cookieFile string
+	// accessedAfter matches cookies last accessed after the time
+	accessedAfter time.Time
+	// accessWindow matches cookies last accessed within the duration
+	accessWindow time.Duration
+	// emptyNameErr returns an error for cookies with empty names, instead of
+	// skipping them
+	emptyNameErr bool
+	// includeExpired includes expired cookies
+	includeExpired bool
+	// excludeSession excludes session cookies
+	excludeSession bool
+	// dedupeOrigins, preferOrigin and originErr control resolution of
+	// cookies that differ only by origin attributes
+	dedupeOrigins bool
+	preferOrigin  string
+	originErr     bool
+	// sameSiteNone is the policy for SameSite=None cookies without Secure
+	sameSiteNone SameSiteNonePolicy
+	// defaultSameSite is the SameSite value used when unspecified
+	defaultSameSite models.SameSite
+	// metadata adds the cookie's metadata to its unparsed attributes
+	metadata bool
+	// decodeValues URL-decodes cookie values
+	decodeValues bool
+	// netscapeExtended writes extended Netscape cookie files
+	netscapeExtended bool
+	// logger is the debug logger
+	logger *slog.Logger
+	// now returns the current time
+	now func() time.Time
+	// tempDir returns the directory for temporary files, which is removed by
+	// its owner. When nil, temporary files are removed once a read completes
+	tempDir func() (string, error)
+	// hosts are the exact cookie hosts to match
+	hosts []string
+	// anyHosts are the hosts whose cookies (and the cookies of their
+	// subdomains) are matched
+	anyHosts []string
+	// names are the cookie names to match
+	names []string
+	// exactHost matches only the cookies for the host itself
+	exactHost bool
+	// domainMatch matches the cookies for the host and its parent domains
+	domainMatch bool
+	// registrable matches cookies for the host's registrable domain
+	registrable bool
+	// partitionSite is the site used to filter partitioned cookies
+	partitionSite      *models.PartitionKey
+	includePartitioned bool
+	// excludePartitioned excludes partitioned cookies
+	excludePartitioned bool
+	// partitionKey is the partition key of the partitioned cookies to match
+	partitionKey *models.PartitionKey
+	// container is the container name or id to match, and userContextID the
+	// container's resolved user context id
+	container     string
+	userContextID int
+	// includePrivate includes private browsing cookies
+	includePrivate bool
+	// sessionStore merges the session cookies from the session store
+	sessionStore bool
+	// publicSuffixList is the public suffix list used when building jars
+	publicSuffixList cookiejar.PublicSuffixList
+}
+
+// newOptions builds the read options.
+func newOptions(opts ...Option) *options {
+	o := &options{
+		openParams:       DefaultOpenParams,
+		now:              time.Now,
+		logger:           slog.New(slog.DiscardHandler),
+		defaultSameSite:  models.SameSiteLax,
+		publicSuffixList: publicsuffix.List,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// baseDir returns the base profile directory, either the one set with
+// [WithProfileDir], the [EnvProfileDir] environment variable, the first
+// existing search path, or the browser's base profile directory.
+func (o *options) baseDir() (string, error) {
+	if o.profileDir != "" {
+		return existingDir(o.profileDir)
+	}
+	if dir := o.getenv(EnvProfileDir); dir != "" {
+		o.logger.Debug("using profile directory from environment", "env", EnvProfileDir)
+		return existingDir(dir)
+	}
+	for _, dir := range o.searchPaths {
+		if osFS.isDir(dir) {
+			return dir, nil
+		}
+	}
+	if o.replaceSearchPaths {
+		return "", fmt.Errorf("%w (tried %s)", ErrProfileDirNotFound, strings.Join(o.searchPaths, ", "))
+	}
+	return o.browser.ProfileDir()
+}
+
+// existingDir returns dir, or [ErrProfileDirNotFound] when dir is not an
+// existing directory.
+func existingDir(dir string) (string, error) {
+	if !osFS.isDir(dir) {
+		return "", fmt.Errorf("%w (tried %s)", ErrProfileDirNotFound, dir)
+	}
+	return dir, nil
+}
+
+// getenv returns the value of the environment variable, or the empty string
+// when disabled with [WithEnv].
+func (o *options) getenv(key string) string {
+	if o.noEnv {
+		return ""
+	}
+	return os.Getenv(key)
+}
+
+// cookiePath resolves the cookie database path for the profile.
+func (o *options) cookiePath(profile string) (string, error) {
+	res, err := o.resolve(profile)
+	if err != nil {
+		return "", err
+	}
+	return res.CookiePath, nil
+}
+
+// resolve resolves the profile, or the default profile when profile is
+// empty.
+func (o *options) resolve(profile string) (*ProfileResolution, error) {
+	switch {
+	case o.cookieFile != "":
+		return &ProfileResolution{
+			Path:       filepath.Dir(o.cookieFile),
+			CookiePath: o.cookieFile,
+			Reason:     ReasonExplicit,
+		}, nil
+	case o.profilePath != "":
+		return &ProfileResolution{
+			Path:       o.profilePath,
+			CookiePath: filepath.Join(o.profilePath, "cookies.sqlite"),
+			Reason:     ReasonExplicit,
+		}, nil
+	case filepath.IsAbs(profile):
+		return absProfile(profile)
+	}
+	profileDir, err := o.baseDir()
+	if err != nil {
+		return nil, err
+	}
+	if profile == "" {
+		profile = o.getenv(EnvProfile)
+	}
+	o.logger.Debug("resolved profile directory", "dir", profileDir)
+	path, reason, err := profileFS{channel: o.channel}.resolvePath(profileDir, profile)
+	if err != nil {
+		return nil, err
+	}
+	res := &ProfileResolution{
+		Dir:        profileDir,
+		Path:       path,
+		CookiePath: filepath.Join(path, "cookies.sqlite"),
+		Reason:     reason,
+	}
+	o.logger.Debug("resolved cookie file", "profile", profile, "path", res.CookiePath, "reason", reason)
+	return res, nil
+}
+
+// absProfile resolves a profile provided as the absolute path of a profile
+// directory or a cookie database.
+func absProfile(profile string) (*ProfileResolution, error) {
+	fi, err := os.Stat(profile)
+	switch {
+	case os.IsNotExist(err):
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, profile)
+	case err != nil:
+		return nil, err
+	case !fi.IsDir():
+		return &ProfileResolution{
+			Path:       filepath.Dir(profile),
+			CookiePath: profile,
+			Reason:     ReasonExplicit,
+		}, nil
+	}
+	cookiePath := filepath.Join(profile, "cookies.sqlite")
+	if !isFile(cookiePath) {
+		return nil, fmt.Errorf("%w: %s", ErrCookieDBNotFound, profile)
+	}
+	return &ProfileResolution{
+		Path:       profile,
+		CookiePath: cookiePath,
+		Reason:     ReasonExplicit,
+	}, nil
+}
+
+// openDB opens the sqlite3 file using the driver set by [WithDriver] or the
+// most preferred registered sqlite3 driver, checking that the connection is
+// usable.
+func (o *options) openDB(ctx context.Context, file string) (*sql.DB, error) {
+	driver := o.driver
+	if driver == "" {
+		driver = driverName()
+	}
+	if driver == "" {
+		return nil, ErrNoDriver
+	}
+	if !strings.HasPrefix(file, "file:") && isFile(file) {
+		// a path on disk, escape the characters drivers treat specially
+		file = fileURI(file, "")
+	}
+	o.logger.Debug("opening database", "driver", driver, "file", file)
+	db, err := sql.Open(driver, file)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite driver %q: %w", driver, err)
+	}
+	if err := o.retry(ctx, func() error {
+		return db.PingContext(ctx)
+	}); err != nil {
+		_ = db.Close()
+		return nil, dbError(file, fmt.Errorf("sqlite driver %q: %w", driver, err))
+	}
+	return db, nil
+}
+
+// open opens the sqlite3 file as a cookie source, returning the source and a
+// func closing it. The session cookies in the session store alongside the
+// sqlite3 file are merged into the source when set by [WithSessionStore].
+func (o *options) open(ctx context.Context, file string) (source, func() error, error) {
+	src, closeSrc, err := o.openFile(ctx, file)
+	if err != nil || !o.sessionStore {
+		return src, closeSrc, err
+	}
+	session, err := o.readSessionStore(filepath.Dir(dbPath(file)))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		o.logger.Debug("no session store", "file", file)
+		return src, closeSrc, nil
+	case err != nil:
+		_ = closeSrc()
+		return nil, nil, err
+	}
+	return mergeSource{src, session}, closeSrc, nil
+}
+
+// openFile opens the sqlite3 file as a cookie source, returning the source
+// and a func closing it. When no sqlite3 driver has been imported (and no
+// driver has been set with [WithDriver]), the file is read without a driver.
+func (o *options) openFile(ctx context.Context, file string) (source, func() error, error) {
+	if err := dbExists(file); err != nil {
+		return nil, nil, err
+	}
+	if err := o.resolveContainer(nil, file); err != nil {
+		return nil, nil, err
+	}
+	if o.driver == "" && driverName() == "" {
+		db, err := o.openNative(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nativeSource{db}, func() error { return nil }, nil
+	}
+	if o.lockStrategy == LockAuto && fileInUse(file) {
+		return o.openCopy(ctx, file)
+	}
+	open := o.openLiveDB
+	if o.snapshot {
+		open = o.openSnapshot
+	}
+	db, closeDB, err := open(ctx, file)
+	switch {
+	case err != nil && (o.lockStrategy == LockCopy || o.lockStrategy == LockAuto) && isBusy(err):
+		return o.openCopy(ctx, file)
+	case err != nil:
+		return nil, nil, err
+	}
+	src, closeSrc := o.lockSource(dbSource{db}, closeDB, file)
+	return src, closeSrc, nil
+}
+
+// openNative reads the sqlite3 file without a sqlite3 driver.
+func (o *options) openNative(file string) (*sqlite.DB, error) {
+	o.logger.Debug("reading database without a sqlite driver", "file", file, "live", o.live)
+	open := sqlite.Open
+	if o.live {
+		open = sqlite.OpenLive
+	}
+	db, err := open(dbPath(file))
+	if err != nil {
+		return nil, dbError(file, err)
+	}
+	return db, nil
+}
+
+// openLiveDB opens the sqlite3 file with a sqlite3 driver, opening a copy of
+// the database and its write-ahead log when reading the live database (see
+// [WithLiveDatabase]). Returns the database and a func closing it.
+func (o *options) openLiveDB(ctx context.Context, file string) (*sql.DB, func() error, error) {
+	closeFile := func() {}
+	if o.live {
+		var err error
+		if file, closeFile, err = o.liveCopy(dbPath(file)); err != nil {
+			return nil, nil, err
+		}
+	}
+	db, err := o.openDB(ctx, file)
+	if err != nil {
+		closeFile()
+		return nil, nil, err
+	}
+	return db, func() error {
+		defer closeFile()
+		return db.Close()
+	}, nil
+}
+
+// liveCopy copies the sqlite3 file and its write-ahead log to a temporary
+// directory, returning the file to open and a func removing the copy.
+//
+// The shared memory (-shm) file is not copied, as it cannot be copied
+// consistently with the write-ahead log while Firefox is running. sqlite3
+// rebuilds it from the write-ahead log when the copy is opened.
+func (o *options) liveCopy(name string) (string, func(), error) {
+	dir, cleanup, err := o.mkTemp()
+	if err != nil {
+		return "", nil, err
+	}
+	dest := filepath.Join(dir, filepath.Base(name))
+	for _, suffix := range []string{"", "-wal"} {
+		switch err := copyFile(dest+suffix, name+suffix); {
+		case suffix != "" && errors.Is(err, fs.ErrNotExist):
+			// no write-ahead log, remove any stale copy
+			_ = os.Remove(dest + suffix)
+		case err != nil:
+			cleanup()
+			return "", nil, err
+		}
+	}
+	_ = os.Remove(dest + "-shm")
+	o.logger.Debug("copied live database", "file", name, "copy", dest)
+	return fileURI(dest, ""), cleanup, nil
+}
+
+// copyFile copies the src file to dst.
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// profileFile returns the sqlite3 file to open for the profile.
+func (o *options) profileFile(profile string) (string, error) {
+	cookiePath, err := o.cookiePath(profile)
+	if err != nil {
+		return "", err
+	}
+	return fileURI(cookiePath, o.openParams), nil
+}
+
+// mkTemp returns a directory for temporary files, and a func that cleans it
+// up once the read has completed.
+func (o *options) mkTemp() (string, func(), error) {
+	if o.tempDir != nil {
+		dir, err := o.tempDir()
+		return dir, func() {}, err
+	}
+	dir, err := os.MkdirTemp("", "ffcookies-")
+	if err != nil {
+		return "", nil, err
+	}
+	return dir, func() { _ = os.RemoveAll(dir) }, nil
+}
+
+// query builds the cookie query for the host and options.
+func (o *options) query(host string) *query {
+	q := new(query)
+	host = asciiHost(host)
+	if etld1, err := publicsuffix.EffectiveTLDPlusOne(host); o.registrable && err == nil {
+		pattern := "%." + escapeLike(etld1)
+		q.and("(host = "+q.arg(etld1)+" OR host LIKE "+q.arg(pattern)+" ESCAPE '\\')", func(c *models.Cookie) bool {
+			return c.Host == etld1 || like(pattern, c.Host)
+		})
+	} else if host != "" && o.exactHost {
+		// firefox stores hosts in lower case
+		host := strings.ToLower(host)
+		q.and("host IN ("+q.arg(host)+", "+q.arg("."+host)+")", func(c *models.Cookie) bool {
+			return c.Host == host || c.Host == "."+host
+		})
+	} else if host != "" && o.domainMatch {
+		var params []string
+		hosts := hostCandidates(strings.ToLower(host), o.publicSuffixList)
+		for _, h := range hosts {
+			params = append(params, q.arg(h))
+		}
+		q.and("host IN ("+strings.Join(params, ", ")+")", func(c *models.Cookie) bool {
+			return slices.Contains(hosts, c.Host)
+		})
+	} else if host != "" {
+		pattern := "%" + escapeLike(strings.TrimPrefix(host, "%"))
+		q.and("host LIKE "+q.arg(pattern)+" ESCAPE '\\'", func(c *models.Cookie) bool {
+			return like(pattern, c.Host)
+		})
+	}
+	if len(o.hosts) != 0 {
+		var params []string
+		for _, h := range o.hosts {
+			params = append(params, q.arg(h))
+		}
+		hosts := o.hosts
+		q.and("host IN ("+strings.Join(params, ", ")+")", func(c *models.Cookie) bool {
+			return slices.Contains(hosts, c.Host)
+		})
+	}
+	if len(o.anyHosts) != 0 {
+		var conds, patterns []string
+		for _, h := range o.anyHosts {
+			pattern := "%" + escapeLike(strings.TrimPrefix(asciiHost(h), "%"))
+			conds, patterns = append(conds, "host LIKE "+q.arg(pattern)+" ESCAPE '\\'"), append(patterns, pattern)
+		}
+		q.and("("+strings.Join(conds, " OR ")+")", func(c *models.Cookie) bool {
+			return slices.ContainsFunc(patterns, func(pattern string) bool {
+				return like(pattern, c.Host)
+			})
+		})
+	}
+	if len(o.names) != 0 {
+		var params []string
+		for _, name := range o.names {
+			params = append(params, q.arg(name))
+		}
+		names := o.names
+		q.and("name IN ("+strings.Join(params, ", ")+")", func(c *models.Cookie) bool {
+			return slices.Contains(names, c.Name)
+		})
+	}
+	accessedAfter := o.accessedAfter
+	if t := o.now().Add(-o.accessWindow); o.accessWindow > 0 && t.After(accessedAfter) {
+		accessedAfter = t
+	}
+	if !accessedAfter.IsZero() {
+		// lastAccessed is stored in microseconds since the epoch
+		micros := accessedAfter.UnixMicro()
+		q.and("lastAccessed > "+q.arg(micros), func(c *models.Cookie) bool {
+			return c.LastAccessed > micros
+		})
+	}
+	if !o.includeExpired {
+		// expiry is stored in seconds since the epoch, with 0 for session
+		// cookies
+		now := o.now().Unix()
+		q.and("(expiry = 0 OR expiry > "+q.arg(now)+")", func(c *models.Cookie) bool {
+			return c.Expiry == 0 || c.Expiry > now
+		})
+	}
+	if o.excludeSession {
+		q.and("expiry <> 0", func(c *models.Cookie) bool {
+			return !c.Session()
+		})
+	}
+	if o.excludePartitioned && o.partitionKey == nil {
+		q.originAttr("partitionKey", "", false)
+	}
+	if o.partitionKey != nil {
+		q.originAttr("partitionKey", "", true)
+	}
+	if !o.includePrivate {
+		// privateBrowsingId is omitted outside of private browsing
+		q.originAttr("privateBrowsingId", "", false)
+	}
+	if o.container != "" {
+		// userContextId is omitted for the default (0) user context
+		if o.userContextID == 0 {
+			q.originAttr("userContextId", "", false)
+		} else {
+			q.originAttr("userContextId", strconv.Itoa(o.userContextID), true)
+		}
+	}
+	return q
+}
+
+// WithProfile is a read option to set the Firefox profile name (or profile
+// directory name) to read. Defaults to the default Firefox profile.
+func WithProfile(profile string) Option {
+	return func(o *options) {
+		o.profile = profile
+	}
+}
+
+// WithHost is a read option to only read the cookies for the host and its
+// subdomains. Defaults to all hosts.
+//
+// The host is matched as a suffix of the cookie host, without regard to label
+// boundaries: reading the cookies for le.com also returns the cookies for
+// google.com. Use [WithExactHost] to only read the cookies set for the host
+// itself, [WithDomainMatch] to read the cookies a browser would send to the
+// host, or [WithRegistrableDomain] to read the cookies for the host's
+// registrable domain and all of its subdomains.
+func WithHost(host string) Option {
+	return func(o *options) {
+		o.host = host
+	}
+}
+
+// WithExactHost is a read option to only read the cookies set for the host
+// itself (including domain cookies for the host, with a leading dot), instead
+// of the cookies for any host ending with the host. For example, reading the
+// cookies for le.com does not return the cookies for google.com or
+// www.le.com.
+func WithExactHost() Option {
+	return func(o *options) {
+		o.exactHost = true
+	}
+}
+
+// WithDomainMatch is a read option to read the cookies that domain-match the
+// host: the cookies for the host itself and for its parent domains, up to the
+// host's registrable domain (as determined by the public suffix list), but
+// not the cookies for its subdomains or other hosts with the same suffix. For
+// example, reading the cookies for app.foo.co.uk returns the cookies for
+// app.foo.co.uk and .foo.co.uk, but not for otherfoo.co.uk. Used by default
+// when building cookie jars for a url.
+func WithDomainMatch() Option {
+	return func(o *options) {
+		o.domainMatch = true
+	}
+}
+
+// WithHosts is a read option to read the cookies for any of the hosts (and
+// their subdomains) in a single query, such as for a set of related hosts.
+// Each host is matched as a suffix of the cookie host, as with [WithHost].
+func WithHosts(hosts ...string) Option {
+	return func(o *options) {
+		o.anyHosts = hosts
+	}
+}
+
+// WithNames is a read option to only read the cookies with the names, such as
+// a single session cookie. Names are matched exactly.
+func WithNames(names ...string) Option {
+	return func(o *options) {
+		o.names = names
+	}
+}
+
+// WithProfileDir is a read option to set the base Firefox profile directory
+// (the directory containing profiles.ini) used to resolve the profile,
+// bypassing [ProfileDir].
+func WithProfileDir(dir string) Option {
+	return func(o *options) {
+		o.profileDir = dir
+	}
+}
+
+// WithBrowser is a read option to set the Firefox based browser whose profiles
+// are read, when no base profile directory has been set with
+// [WithProfileDir]. Defaults to [Firefox].
+func WithBrowser(browser Browser) Option {
+	return func(o *options) {
+		o.browser = browser
+	}
+}
+
+// WithSearchPaths is a read option to set the base profile directories
+// searched instead of the browser's base profile directories (see
+// [Browser.ProfileDirs]), such as for portable installs or nonstandard
+// locations. The first existing directory is used.
+func WithSearchPaths(dirs ...string) Option {
+	return func(o *options) {
+		o.searchPaths, o.replaceSearchPaths = dirs, true
+	}
+}
+
+// WithPrependSearchPaths is a read option to add base profile directories
+// that are searched before the browser's base profile directories. The first
+// existing directory is used, and when none exist, the browser's base profile
+// directory is used.
+func WithPrependSearchPaths(dirs ...string) Option {
+	return func(o *options) {
+		o.searchPaths = append(slices.Clone(dirs), o.searchPaths...)
+	}
+}
+
+// WithChannel is a read option to set the Firefox release channel whose
+// default profile is used when no profile name is provided. Defaults to
+// [ChannelAuto].
+func WithChannel(channel Channel) Option {
+	return func(o *options) {
+		o.channel = channel
+	}
+}
+
+// WithSource is a read option to set the cookie source read by
+// [ReadCookies] and [ReadEntries] (and the funcs using them, such as
+// [ReadContext] and [ReadJar]), instead of reading a Firefox profile.
+func WithSource(src CookieSource) Option {
+	return func(o *options) {
+		o.source = src
+	}
+}
+
+// WithConcurrency is a read option to set the maximum number of profiles read
+// at the same time by [ReadParallel]. Defaults to GOMAXPROCS.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithUsersDir is a read option to set the directory containing the users'
+// home directories scanned by [ScanSystem]. Defaults to /home (and root's home
+// directory), /Users on macOS, and C:\Users on Windows.
+func WithUsersDir(dir string) Option {
+	return func(o *options) {
+		o.usersDir = dir
+	}
+}
+
+// WithEnv is a read option to toggle using the [EnvProfileDir] and
+// [EnvProfile] environment variables when resolving the profile. Defaults to
+// true.
+func WithEnv(env bool) Option {
+	return func(o *options) {
+		o.noEnv = !env
+	}
+}
+
+// WithOpenParams is a read option to set the sqlite3 open parameters used when
+// opening a profile's cookie database. Defaults to [DefaultOpenParams].
+func WithOpenParams(params string) Option {
+	return func(o *options) {
+		o.openParams = params
+	}
+}
+
+// WithDriver is a read option to set the database/sql driver name used to open
+// the sqlite3 database. Defaults to the most preferred driver registered with
+// [RegisterDriverName].
+func WithDriver(name string) Option {
+	return func(o *options) {
+		o.driver = name
+	}
+}
+
+// WithLiveDatabase is a read option to read the live state of a cookie
+// database in use by a running Firefox, including the recently set cookies
+// stored in the database's write-ahead log (the cookies.sqlite-wal file).
+//
+// By default, the database is opened as an immutable snapshot (see
+// [DefaultOpenParams]), which ignores the write-ahead log. When reading with
+// a sqlite3 driver, the database and its write-ahead log are copied to a
+// temporary directory, and the copy is opened instead (ignoring the open
+// parameters).
+func WithLiveDatabase() Option {
+	return func(o *options) {
+		o.live = true
+	}
+}
+
+// WithSnapshot is a read option to read from a snapshot of the cookie
+// database written to a temporary directory (see [Snapshot]), rather than the
+// database itself, so that long running reads (such as iterating
+// [CookiesIter]) do not keep the database open.
+//
+// Has no effect when reading without a sqlite3 driver, as the database is
+// read into memory when opened.
+func WithSnapshot() Option {
+	return func(o *options) {
+		o.snapshot = true
+	}
+}
+
+// WithRetry is a read option to set the policy for retrying reads that fail
+// because the cookie database is busy or locked. Reads are not retried by
+// default. See [DefaultRetryPolicy].
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retryPolicy = policy
+	}
+}
+
+// WithLockStrategy is a read option to set the strategy for reading a cookie
+// database that is busy or locked. Defaults to [LockFail].
+func WithLockStrategy(strategy LockStrategy) Option {
+	return func(o *options) {
+		o.lockStrategy = strategy
+	}
+}
+
+// WithAccessedAfter is a read option to only return cookies last accessed
+// after t.
+func WithAccessedAfter(t time.Time) Option {
+	return func(o *options) {
+		o.accessedAfter = t
+	}
+}
+
+// WithAccessWindow is a read option to only return cookies last accessed
+// within the window d before the current time, on the basis that stale
+// session cookies are likely logged out. Useful when building cookie jars for
+// session sensitive automation. A window of 0 (the default) disables pruning.
+func WithAccessWindow(d time.Duration) Option {
+	return func(o *options) {
+		o.accessWindow = d
+	}
+}
+
+// WithProfilePath is a read option to use dir as the profile directory,
+// bypassing the resolution of the firefox profile directory and the profile
+// name.
+func WithProfilePath(dir string) Option {
+	return func(o *options) {
+		o.profilePath = dir
+	}
+}
+
+// WithCookieFile is a read option to read cookies from the sqlite3 file on
+// disk, bypassing all profile resolution. Takes precedence over
+// [WithProfilePath].
+func WithCookieFile(file string) Option {
+	return func(o *options) {
+		o.cookieFile = file
+	}
+}
+
+// WithEmptyNameError is a read option to return an error listing the rows
+// with empty cookie names, instead of skipping them.
+func WithEmptyNameError() Option {
+	return func(o *options) {
+		o.emptyNameErr = true
+	}
+}
+
+// WithIncludeExpired is a read option to control whether expired cookies are
+// included. Expired cookies are excluded by default, using the current time
+// as determined by [WithClock]. Session cookies are not expired.
+func WithIncludeExpired(include bool) Option {
+	return func(o *options) {
+		o.includeExpired = include
+	}
+}
+
+// WithIncludeSession is a read option to control whether session cookies
+// (which Firefox stores with an expiry of 0, and are converted without an
+// expiry) are included. Session cookies are included by default.
+func WithIncludeSession(include bool) Option {
+	return func(o *options) {
+		o.excludeSession = !include
+	}
+}
+
+// WithPublicSuffixList is a read option to set the public suffix list used
+// when building cookie jars, and when reading the cookies for a host's parent
+// domains (see [WithDomainMatch]). A nil list disables public suffix checks,
+// which allows domain cookies for public suffixes used on intranets (such as
+// home.arpa) to be stored. Defaults to [publicsuffix.List].
+func WithPublicSuffixList(list cookiejar.PublicSuffixList) Option {
+	return func(o *options) {
+		o.publicSuffixList = list
+	}
+}
+
+// WithPreferOriginAttributes is a read option to set the origin attributes
+// (for example, "^userContextId=1" for a container) preferred when building a
+// cookie jar from cookies that differ only by origin attributes. Defaults to
+// the empty origin attributes used outside of containers.
+//
+// A cookie jar cannot represent container isolation, so only one of the
+// cookies is added to the jar: the cookie with the preferred origin
+// attributes, otherwise the cookie with empty origin attributes, otherwise
+// the most recently accessed cookie. The resolved cookies are logged at the
+// warn level (see [WithLogger]), or reported as an error with
+// [WithOriginCollisionError].
+func WithPreferOriginAttributes(attrs string) Option {
+	return func(o *options) {
+		o.preferOrigin = attrs
+	}
+}
+
+// WithOriginCollisionError is a read option to return [ErrOriginCollision]
+// when building a cookie jar from cookies that differ only by origin
+// attributes, instead of resolving them as described by
+// [WithPreferOriginAttributes].
+func WithOriginCollisionError() Option {
+	return func(o *options) {
+		o.originErr = true
+	}
+}
+
+// WithSameSiteNonePolicy is a read option to set the policy for SameSite=None
+// cookies without the Secure attribute. Applies to all reads and exports.
+// Defaults to [SameSiteNoneKeep].
+func WithSameSiteNonePolicy(policy SameSiteNonePolicy) Option {
+	return func(o *options) {
+		o.sameSiteNone = policy
+	}
+}
+
+// WithDefaultSameSite is a read option to set the SameSite value used for
+// cookies where neither the sameSite nor the rawSameSite value is set.
+// Defaults to [models.SameSiteLax], matching current Firefox versions. Use
+// [models.SameSiteNone] or [models.SameSiteUnset] to emulate older Firefox
+// versions. See [models.Cookie.EffectiveSameSite].
+func WithDefaultSameSite(sameSite models.SameSite) Option {
+	return func(o *options) {
+		o.defaultSameSite = sameSite
+	}
+}
+
+// WithContainer is a read option to read only the cookies of a Firefox
+// Multi-Account Container, specified by its user context id or by its name
+// (case insensitive) as listed in the profile's containers.json. Use "0" to
+// read only the cookies outside of containers. See [ReadContainers].
+func WithContainer(nameOrID string) Option {
+	return func(o *options) {
+		o.container = nameOrID
+	}
+}
+
+// WithIncludePrivate is a read option to control whether cookies created in
+// private browsing windows (having a privateBrowsingId origin attribute) are
+// included. Private browsing cookies are excluded by default, matching the
+// cookies sent in a normal browsing session.
+func WithIncludePrivate(include bool) Option {
+	return func(o *options) {
+		o.includePrivate = include
+	}
+}
+
+// WithSessionStore is a read option to merge the session cookies from the
+// profile's session store (see [ReadSessionStore]) into the cookies read from
+// the cookie database. Cookies in the database take precedence over session
+// store cookies with the same name, host, path and origin attributes. No
+// cookies are merged when the profile does not have a session store.
+func WithSessionStore() Option {
+	return func(o *options) {
+		o.sessionStore = true
+	}
+}
+
+// WithExcludePartitioned is a read option to exclude partitioned cookies
+// (cookies having a partitionKey origin attribute, such as cookies set with
+// the Partitioned (CHIPS) attribute by embedded third-party sites).
+func WithExcludePartitioned() Option {
+	return func(o *options) {
+		o.excludePartitioned = true
+	}
+}
+
+// WithPartitionKey is a read option to read only the partitioned cookies keyed
+// to the top-level site, such as https://example.com. The site's scheme
+// defaults to https, and its host is reduced to its registrable domain.
+func WithPartitionKey(site string) Option {
+	return func(o *options) {
+		if !strings.Contains(site, "://") {
+			site = "https://" + site
+		}
+		var key models.PartitionKey
+		if u, err := url.Parse(site); err == nil {
+			key.Scheme = siteScheme(strings.ToLower(u.Scheme))
+			key.Host = siteHost(urlHost(u))
+			if port, err := strconv.Atoi(u.Port()); err == nil {
+				key.Port = port
+			}
+		}
+		o.partitionKey = &key
+	}
+}
+
+// WithIncludePartitioned is a read option to control whether partitioned
+// cookies are included. Partitioned cookies are excluded from cookie jars by
+// default, and when included, [CookiesForURL] includes only the partitioned
+// cookies keyed to the requested site.
+func WithIncludePartitioned(include bool) Option {
+	return func(o *options) {
+		o.includePartitioned = include
+		o.excludePartitioned = !include
+	}
+}
+
+// WithClock is a read option to set the func used to determine the current
+// time, such as when filtering expired cookies. Defaults to [time.Now].
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// WithRegistrableDomain is a read option to return the cookies for the host's
+// registrable domain (as determined by the public suffix list) and all of its
+// subdomains, instead of only those for the host and its subdomains. For
+// example, reading the cookies for app.example.co.uk also returns the cookies
+// for example.co.uk and www.example.co.uk.
+func WithRegistrableDomain() Option {
+	return func(o *options) {
+		o.registrable = true
+	}
+}
+
+// WithLogger is a read option to set a logger for debug messages, such as the
+// resolved profile directory, cookie file path, sqlite driver, and the number
+// of rows read.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		if logger == nil {
+			logger = slog.New(slog.DiscardHandler)
+		}
+		o.logger = logger
+	}
+}
+
+// WithMetadata is a read option to add the Firefox origin attributes,
+// creation time and last accessed time of each cookie to the cookie's
+// Unparsed attributes. See [Metadata].
+func WithMetadata() Option {
+	return func(o *options) {
+		o.metadata = true
+	}
+}
+
+// WithDecodeValues is a read option to percent-decode cookie values once, for
+// servers that store already encoded values that would otherwise be encoded
+// twice. Values that are not validly encoded are left as-is.
+func WithDecodeValues() Option {
+	return func(o *options) {
+		o.decodeValues = true
+	}
+}
+
+// WithNetscapeExtended is a read option to write the extended Netscape cookie
+// file format in [ExportAllNetscape]. See [WriteNetscapeExtended].
+func WithNetscapeExtended() Option {
+	return func(o *options) {
+		o.netscapeExtended = true
+		o.metadata = true
+	}
+}
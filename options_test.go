@@ -0,0 +1,320 @@
+package ffcookies
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kenshaw/ffcookies/models"
+)
+
+func TestWithAccessedAfter(t *testing.T) {
+	tests := []struct {
+		after time.Time
+		exp   []string
+	}{
+		{time.Time{}, []string{"new", "old"}},
+		{testNow, []string{"new"}},
+		{time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), []string{"new"}},
+		{time.Date(2024, 5, 31, 23, 59, 59, 999999000, time.UTC), []string{"new", "old"}},
+		{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), nil},
+	}
+	for _, test := range tests {
+		t.Run(test.after.Format(time.RFC3339Nano), func(t *testing.T) {
+			cookies, err := ReadFile(testDB, "accessed.test", WithAccessedAfter(test.after))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if names := cookieNames(cookies); !slices.Equal(names, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, names)
+			}
+		})
+	}
+}
+
+func TestWithProfilePath(t *testing.T) {
+	dir := t.TempDir()
+	mkProfile(t, filepath.Join(dir, "profile"), testNow)
+	// without a base profile directory
+	t.Setenv("HOME", filepath.Join(dir, "home"))
+	t.Setenv(EnvProfileDir, filepath.Join(dir, "missing"))
+	opts := []Option{
+		WithProfileDir(filepath.Join(dir, "missing")),
+		WithProfilePath(filepath.Join(dir, "profile")),
+	}
+	res, err := ResolveProfile(context.Background(), "", opts...)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if exp := filepath.Join(dir, "profile", "cookies.sqlite"); res.CookiePath != exp {
+		t.Errorf("expected %s, got: %s", exp, res.CookiePath)
+	}
+	if res.Dir != "" || res.Reason != ReasonExplicit {
+		t.Errorf("expected explicit profile, got: %q %q", res.Dir, res.Reason)
+	}
+	cookies, err := ReadContext(context.Background(), "", "accessed.test", opts...)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if names, exp := cookieNames(cookies), []string{"new", "old"}; !slices.Equal(names, exp) {
+		t.Errorf("expected %v, got: %v", exp, names)
+	}
+	// cookie file takes precedence
+	file := filepath.Join(dir, "other.sqlite")
+	res, err = ResolveProfile(context.Background(), "", append(opts, WithCookieFile(file))...)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if res.CookiePath != file {
+		t.Errorf("expected %s, got: %s", file, res.CookiePath)
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	tests := []struct {
+		now time.Time
+		exp []string
+	}{
+		{testNow, []string{"later", "session", "soon"}},
+		{time.Date(2025, 5, 31, 23, 59, 59, 0, time.UTC), []string{"later", "session", "soon"}},
+		{time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), []string{"later", "session"}},
+		{time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC), []string{"session"}},
+	}
+	for _, test := range tests {
+		t.Run(test.now.Format(time.RFC3339), func(t *testing.T) {
+			cookies, err := ReadFile(testDB, "clock.test", clockAt(test.now))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if names := cookieNames(cookies); !slices.Equal(names, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, names)
+			}
+		})
+	}
+}
+
+func TestWithIncludeExpired(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		opts []Option
+		exp  []string
+		cond bool
+	}{
+		{"default", nil, []string{"later", "session"}, true},
+		{"exclude", []Option{WithIncludeExpired(false)}, []string{"later", "session"}, true},
+		{"include", []Option{WithIncludeExpired(true)}, []string{"later", "session", "soon"}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := append([]Option{clockAt(now)}, test.opts...)
+			cookies, err := ReadFile(testDB, "clock.test", opts...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if names := cookieNames(cookies); !slices.Equal(names, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, names)
+			}
+			// expired cookies are excluded in the query
+			where := newOptions(opts...).query("clock.test").where()
+			if cond := strings.Contains(where, "expiry > "); cond != test.cond {
+				t.Errorf("expected expiry condition %t, got: %q", test.cond, where)
+			}
+		})
+	}
+}
+
+func TestWithRegistrableDomain(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		opts []Option
+		exp  []string
+	}{
+		{"default", "app.example.co.uk", nil, []string{"app"}},
+		{"registrable", "app.example.co.uk", []Option{WithRegistrableDomain()}, []string{"app", "site", "www"}},
+		{"registrable domain", "example.co.uk", []Option{WithRegistrableDomain()}, []string{"app", "site", "www"}},
+		{"domain match", "app.example.co.uk", []Option{WithDomainMatch()}, []string{"app", "site"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cookies, err := ReadFile(testDB, test.host, test.opts...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if names := cookieNames(cookies); !slices.Equal(names, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, names)
+			}
+		})
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	dir := t.TempDir()
+	mkProfile(t, filepath.Join(dir, "a.default-release"), testNow)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}))
+	cookies, err := ReadContext(context.Background(), "", "accessed.test", WithProfileDir(dir), WithEnv(false), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	records := make(map[string]map[string]any)
+	for dec := json.NewDecoder(&buf); dec.More(); {
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		records[m["msg"].(string)] = m
+	}
+	if m, exp := records["resolved profile directory"], dir; m == nil || m["dir"] != exp {
+		t.Errorf("expected resolved profile directory %s, got: %v", exp, m)
+	}
+	if m, exp := records["resolved cookie file"], filepath.Join(dir, "a.default-release", "cookies.sqlite"); m == nil || m["path"] != exp {
+		t.Errorf("expected resolved cookie file %s, got: %v", exp, m)
+	}
+	if m, exp := records["read cookies"], float64(len(cookies)); m == nil || m["rows"] != exp {
+		t.Errorf("expected %v rows read, got: %v", exp, m)
+	}
+	// nil logger
+	if _, err := ReadFile(testDB, "accessed.test", WithLogger(nil)); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestWithAccessWindow(t *testing.T) {
+	now := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		opts []Option
+		exp  []string
+	}{
+		{"disabled", nil, []string{"new", "old"}},
+		{"stale", []Option{WithAccessWindow(30 * 24 * time.Hour)}, []string{"new"}},
+		{"fresh", []Option{WithAccessWindow(2 * 365 * 24 * time.Hour)}, []string{"new", "old"}},
+		{"accessed after", []Option{WithAccessWindow(2 * 365 * 24 * time.Hour), WithAccessedAfter(testNow)}, []string{"new"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jar, err := ReadJar("", "https://accessed.test", append([]Option{WithCookieFile(testDB), clockAt(now)}, test.opts...)...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if names := jarNames(t, jar, "https://accessed.test"); !slices.Equal(names, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, names)
+			}
+		})
+	}
+}
+
+func TestWithDecodeValues(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		exp  map[string]string
+	}{
+		{"default", nil, map[string]string{"space": "a%20b", "double": "a%2520b", "invalid": "100%"}},
+		{"decode", []Option{WithDecodeValues()}, map[string]string{"space": "a b", "double": "a%20b", "invalid": "100%"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cookies, err := ReadFile(testDB, "decode.test", test.opts...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if values := cookieValues(cookies); !maps.Equal(values, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, values)
+			}
+		})
+	}
+}
+
+func TestOpenDBPingError(t *testing.T) {
+	_, err := ReadFile(testDB, "accessed.test", WithDriver(pingDriverName))
+	switch {
+	case !errors.Is(err, errPing):
+		t.Fatalf("expected ping error, got: %v", err)
+	case !strings.Contains(err.Error(), `sqlite driver "`+pingDriverName+`"`):
+		t.Errorf("expected error to name the driver, got: %v", err)
+	}
+}
+
+// pingDriverName is the name of the test driver whose connections fail to
+// ping.
+const pingDriverName = "ffcookies-test-ping"
+
+// errPing is the test driver's ping error.
+var errPing = errors.New("unsupported file uri")
+
+func init() {
+	sql.Register(pingDriverName, pingDriver{})
+}
+
+// pingDriver is a database/sql driver whose connections fail to ping.
+type pingDriver struct{}
+
+// Open satisfies the [driver.Driver] interface.
+func (pingDriver) Open(string) (driver.Conn, error) {
+	return pingConn{}, nil
+}
+
+// pingConn is a connection that fails to ping.
+type pingConn struct{}
+
+// Prepare satisfies the [driver.Conn] interface.
+func (pingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+// Close satisfies the [driver.Conn] interface.
+func (pingConn) Close() error {
+	return nil
+}
+
+// Begin satisfies the [driver.Conn] interface.
+func (pingConn) Begin() (driver.Tx, error) {
+	return nil, driver.ErrSkip
+}
+
+// Ping satisfies the [driver.Pinger] interface.
+func (pingConn) Ping(context.Context) error {
+	return errPing
+}
+
+func TestWithDefaultSameSite(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		exp  http.SameSite
+	}{
+		{"default", nil, http.SameSiteLaxMode},
+		{"strict", []Option{WithDefaultSameSite(models.SameSiteStrict)}, http.SameSiteStrictMode},
+		{"unset", []Option{WithDefaultSameSite(models.SameSiteUnset)}, http.SameSiteDefaultMode},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cookies, err := ReadFile(testDB, "www.stats.test", append([]Option{clockAt(testNow)}, test.opts...)...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if names, exp := cookieNames(cookies), []string{"d"}; !slices.Equal(names, exp) {
+				t.Fatalf("expected %v, got: %v", exp, names)
+			}
+			if cookies[0].SameSite != test.exp {
+				t.Errorf("expected %d, got: %d", test.exp, cookies[0].SameSite)
+			}
+		})
+	}
+}
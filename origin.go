@@ -0,0 +1,75 @@
+package ffcookies
+
+import (
+	"github.com/kenshaw/ffcookies/models"
+)
+
+// dedupeOrigins resolves cookies sharing the same name, host and path but
+// having different origin attributes (for example, the same cookie in
+// multiple containers, or a partitioned cookie keyed to multiple top-level
+// sites), keeping a single cookie for each name, host and path.
+//
+// A cookie jar cannot represent container isolation, so only one such cookie
+// can be stored. The cookie kept is the first of:
+//
+//   - the cookie with the preferred origin attributes
+//   - the cookie with empty origin attributes (outside of containers)
+//   - the most recently accessed cookie, or the first cookie read when
+//     multiple cookies were last accessed at the same time
+//
+// Returns the cookies, and the cookies that collided (all cookies of each
+// colliding name, host and path) for reporting.
+func dedupeOrigins(res []*models.Cookie, prefer string) ([]*models.Cookie, []*models.Cookie) {
+	type key struct {
+		name, host, path string
+	}
+	groups := make(map[key][]*models.Cookie)
+	var keys []key
+	for _, c := range res {
+		k := key{c.Name, c.Host, c.Path}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], c)
+	}
+	var cookies, collisions []*models.Cookie
+	for _, k := range keys {
+		g := groups[k]
+		if len(g) == 1 {
+			cookies = append(cookies, g[0])
+			continue
+		}
+		collisions = append(collisions, g...)
+		cookies = append(cookies, preferOrigin(g, prefer))
+	}
+	return cookies, collisions
+}
+
+// preferOrigin returns the cookie to keep from cookies differing only by
+// origin attributes. See [dedupeOrigins].
+func preferOrigin(g []*models.Cookie, prefer string) *models.Cookie {
+	for _, attrs := range []string{prefer, ""} {
+		for _, c := range g {
+			if c.OriginAttributes == attrs {
+				return c
+			}
+		}
+	}
+	newest := g[0]
+	for _, c := range g[1:] {
+		if c.LastAccessed > newest.LastAccessed {
+			newest = c
+		}
+	}
+	return newest
+}
+
+// originKeys returns the name, host, path and origin attributes of the
+// cookies, for logging.
+func originKeys(res []*models.Cookie) []string {
+	keys := make([]string, 0, len(res))
+	for _, c := range res {
+		keys = append(keys, c.Name+" ("+c.Host+c.Path+c.OriginAttributes+")")
+	}
+	return keys
+}
@@ -0,0 +1,95 @@
+package ffcookies
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kenshaw/ffcookies/models"
+)
+
+func TestReadJarOriginAttributes(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		exp  map[string]string
+	}{
+		{"default", nil, map[string]string{"k": "c2", "d": "default"}},
+		{"prefer", []Option{WithPreferOriginAttributes("^userContextId=1")}, map[string]string{"k": "c1", "d": "c1"}},
+		{"include partitioned", []Option{WithIncludePartitioned(true)}, map[string]string{"k": "c2", "d": "default", "p": "partitioned"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jar, err := ReadJar("", "https://container.test", append([]Option{WithCookieFile(testDB)}, test.opts...)...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if values := cookieValues(jarCookies(t, jar, "https://container.test")); !maps.Equal(values, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, values)
+			}
+		})
+	}
+}
+
+func TestDedupeOrigins(t *testing.T) {
+	res := []*models.Cookie{
+		{Name: "a", Host: ".example.com", Path: "/", OriginAttributes: "^userContextId=1", LastAccessed: 1},
+		{Name: "a", Host: ".example.com", Path: "/", OriginAttributes: "^userContextId=2", LastAccessed: 1},
+		{Name: "a", Host: "example.com", Path: "/", OriginAttributes: "^userContextId=2"},
+		{Name: "b", Host: ".example.com", Path: "/"},
+	}
+	cookies, collisions := dedupeOrigins(res, "")
+	if len(cookies) != 3 {
+		t.Fatalf("expected 3 cookies, got: %d", len(cookies))
+	}
+	// first cookie read on a tie
+	if cookies[0] != res[0] {
+		t.Errorf("expected first cookie, got: %v", cookies[0])
+	}
+	if len(collisions) != 2 || collisions[0] != res[0] || collisions[1] != res[1] {
+		t.Errorf("expected 2 collisions, got: %v", originKeys(collisions))
+	}
+	cookies, _ = dedupeOrigins(res, "^userContextId=2")
+	if cookies[0] != res[1] {
+		t.Errorf("expected preferred cookie, got: %v", cookies[0])
+	}
+}
+
+func TestOriginCollisionReport(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	if _, err := ReadJar("", "https://container.test", WithCookieFile(testDB), WithLogger(logger)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var m map[string]any
+	if err := json.NewDecoder(&buf).Decode(&m); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	switch cookies, _ := m["cookies"].([]any); {
+	case m["level"] != "WARN" || m["msg"] != "resolved cookies differing only by origin attributes":
+		t.Errorf("expected collision warning, got: %v", m)
+	case len(cookies) != 4:
+		t.Errorf("expected 4 colliding cookies, got: %v", m["cookies"])
+	}
+	_, err := ReadJar("", "https://container.test", WithCookieFile(testDB), WithOriginCollisionError())
+	switch {
+	case !errors.Is(err, ErrOriginCollision):
+		t.Fatalf("expected %v, got: %v", ErrOriginCollision, err)
+	case !strings.Contains(err.Error(), "k (.container.test/^userContextId=1)"):
+		t.Errorf("expected error listing the colliding cookies, got: %v", err)
+	}
+}
+
+// cookieValues returns the values of the cookies by name.
+func cookieValues(cookies []*http.Cookie) map[string]string {
+	values := make(map[string]string)
+	for _, cookie := range cookies {
+		values[cookie.Name] = cookie.Value
+	}
+	return values
+}
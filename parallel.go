@@ -0,0 +1,72 @@
+package ffcookies
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"runtime"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ProfileResult is the result of reading a profile. See [ReadParallel].
+type ProfileResult struct {
+	// Profile is the profile, as provided.
+	Profile string
+	// Cookies are the profile's cookies.
+	Cookies []*http.Cookie
+	// Err is the error reading the profile.
+	Err error
+}
+
+// ReadParallel reads the cookies for the host from each of the provided
+// Firefox profiles concurrently, returning an iterator over each profile's
+// result as its read completes. Profiles are resolved the same as with
+// [ReadContext], and can be the absolute path of a profile directory or a
+// cookie database.
+//
+// At most the number of profiles set by [WithConcurrency] are read at the
+// same time. An error reading a profile is returned on its result, and does
+// not stop the other reads. Remaining reads are canceled when iteration
+// stops.
+func ReadParallel(ctx context.Context, profiles []string, host string, opts ...Option) iter.Seq[*ProfileResult] {
+	return func(yield func(*ProfileResult) bool) {
+		o := newOptions(opts...)
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		ch := make(chan *ProfileResult)
+		go func() {
+			defer close(ch)
+			var eg errgroup.Group
+			eg.SetLimit(o.parallelism())
+			for _, profile := range profiles {
+				if ctx.Err() != nil {
+					break
+				}
+				eg.Go(func() error {
+					// each read uses its own options, as reads modify them
+					cookies, err := ReadContext(ctx, profile, host, opts...)
+					select {
+					case ch <- &ProfileResult{Profile: profile, Cookies: cookies, Err: err}:
+					case <-ctx.Done():
+					}
+					return nil
+				})
+			}
+			_ = eg.Wait()
+		}()
+		for res := range ch {
+			if !yield(res) {
+				return
+			}
+		}
+	}
+}
+
+// parallelism returns the number of concurrent reads.
+func (o *options) parallelism() int {
+	if o.concurrency > 0 {
+		return o.concurrency
+	}
+	return runtime.GOMAXPROCS(0)
+}
@@ -0,0 +1,630 @@
+package ffcookies
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Profile is a Firefox profile.
+type Profile struct {
+	// Name is the profile's name, or the base name of its directory when the
+	// profile is not listed in profiles.ini.
+	Name string `json:"name"`
+	// Path is the profile's directory.
+	Path string `json:"path"`
+	// IsRelative is true when the profile's directory is relative to the
+	// Firefox profile directory.
+	IsRelative bool `json:"is_relative"`
+	// Default is true when the profile is marked as the default in
+	// profiles.ini.
+	Default bool `json:"default"`
+}
+
+// ProfileReason is the reason a profile was chosen. See [ResolveProfile].
+type ProfileReason string
+
+// Profile reasons.
+const (
+	// ReasonExplicit is when the profile or cookie database path was set
+	// with [WithProfilePath] or [WithCookieFile], or was provided as an
+	// absolute path.
+	ReasonExplicit ProfileReason = "explicit"
+	// ReasonNamed is when the profile was chosen by its name.
+	ReasonNamed ProfileReason = "named"
+	// ReasonInstallDefault is when the profile is an install's default
+	// profile in installs.ini.
+	ReasonInstallDefault ProfileReason = "install default"
+	// ReasonProfilesDefault is when the profile is marked as the default in
+	// profiles.ini.
+	ReasonProfilesDefault ProfileReason = "profiles.ini default"
+	// ReasonChannelSuffix is when the profile's directory name is a
+	// channel's default profile.
+	ReasonChannelSuffix ProfileReason = "channel suffix"
+	// ReasonMostRecentlyUsed is when the profile was the most recently used,
+	// by the modification time of its times.json and prefs.js.
+	ReasonMostRecentlyUsed ProfileReason = "most recently used"
+	// ReasonNewestCookies is when the profile had the most recently modified
+	// cookie database.
+	ReasonNewestCookies ProfileReason = "newest cookies"
+)
+
+// ProfileResolution is a resolved profile.
+type ProfileResolution struct {
+	// Dir is the base profile directory, or empty when the profile was set
+	// explicitly.
+	Dir string `json:"dir,omitempty"`
+	// Path is the profile's directory.
+	Path string `json:"path"`
+	// CookiePath is the path of the profile's cookie database.
+	CookiePath string `json:"cookie_path"`
+	// Reason is the reason the profile was chosen.
+	Reason ProfileReason `json:"reason"`
+}
+
+// ResolveProfile resolves the provided Firefox profile name, or the default
+// Firefox profile, returning the profile that is read with the same options
+// and the reason it was chosen.
+func ResolveProfile(ctx context.Context, profile string, opts ...Option) (*ProfileResolution, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return newOptions(opts...).resolve(profile)
+}
+
+// ListProfilesContext lists the Firefox profiles in the Firefox profile
+// directory. Profiles listed in profiles.ini are returned first, in order,
+// followed by the install defaults in installs.ini and the profile
+// directories not listed in either file.
+func ListProfilesContext(ctx context.Context) ([]Profile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	dir, err := ProfileDir()
+	if err != nil {
+		return nil, err
+	}
+	return osFS.discoverProfiles(dir)
+}
+
+// ListProfiles lists the Firefox profiles in the Firefox profile directory.
+func ListProfiles() ([]Profile, error) {
+	return ListProfilesContext(context.Background())
+}
+
+// discoverProfiles discovers the profiles for the firefox profile directory
+// dir. Profiles are discovered from the Path and IsRelative keys in
+// profiles.ini, the install defaults in installs.ini (both of which may refer
+// to absolute paths outside of dir), and the profile directories in dir.
+func (pfs profileFS) discoverProfiles(dir string) ([]Profile, error) {
+	var profiles []Profile
+	seen := make(map[string]bool)
+	add := func(p Profile) {
+		if p.Name == "" {
+			p.Name = filepath.Base(p.Path)
+		}
+		if !seen[p.Path] {
+			profiles, seen[p.Path] = append(profiles, p), true
+		}
+	}
+	// profiles.ini
+	sections, err := pfs.readIni(pfs.join(dir, "profiles.ini"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, section := range sections {
+		if !strings.HasPrefix(section.Name, "Profile") || section.Keys["Path"] == "" {
+			continue
+		}
+		relative := section.Keys["IsRelative"] != "0"
+		add(Profile{
+			Name:       section.Keys["Name"],
+			Path:       pfs.iniPath(dir, section.Keys["Path"], relative),
+			IsRelative: relative,
+			Default:    section.Keys["Default"] == "1",
+		})
+	}
+	// installs.ini
+	sections, err = pfs.readIni(pfs.join(dir, "installs.ini"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, section := range sections {
+		if path := section.Keys["Default"]; path != "" {
+			relative := !pfs.isAbs(path)
+			add(Profile{
+				Path:       pfs.iniPath(dir, path, relative),
+				IsRelative: relative,
+			})
+		}
+	}
+	// profile directories, which are in the Profiles subdirectory on Windows
+	// and macOS
+	for _, d := range []string{dir, pfs.join(dir, "Profiles")} {
+		entries, err := pfs.readDir(d)
+		switch {
+		case d != dir && os.IsNotExist(err):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		for _, entry := range entries {
+			path := pfs.join(d, entry.Name())
+			if entry.IsDir() && (strings.Contains(entry.Name(), ".") || pfs.isProfile(path)) {
+				add(Profile{
+					Path:       path,
+					IsRelative: true,
+				})
+			}
+		}
+	}
+	return profiles, nil
+}
+
+// isProfile returns true when dir looks like a Firefox profile.
+func (pfs profileFS) isProfile(dir string) bool {
+	for _, name := range []string{"cookies.sqlite", "prefs.js", "times.json"} {
+		if _, err := pfs.stat(pfs.join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// profilePath resolves the path of the profile in the firefox profile
+// directory dir, or the default profile when profile is empty. The profile
+// can be the profile's name or its directory name.
+func (pfs profileFS) profilePath(dir, profile string) (string, error) {
+	path, _, err := pfs.resolvePath(dir, profile)
+	return path, err
+}
+
+// resolvePath resolves the path of the profile in the firefox profile
+// directory dir, as with profilePath, returning the reason the profile was
+// chosen.
+func (pfs profileFS) resolvePath(dir, profile string) (string, ProfileReason, error) {
+	if profile == "" {
+		return pfs.defaultProfile(dir)
+	}
+	profiles, err := pfs.discoverProfiles(dir)
+	if err != nil {
+		return "", "", err
+	}
+	for _, p := range profiles {
+		if p.Name == profile || filepath.Base(p.Path) == profile {
+			return p.Path, ReasonNamed, nil
+		}
+	}
+	if path := pfs.join(dir, profile); pfs.isDir(path) {
+		return path, ReasonNamed, nil
+	}
+	return "", "", fmt.Errorf("%w: %s in %s", ErrProfileNotFound, profile, dir)
+}
+
+// defaultSuffixes are the directory name suffixes of default profiles created
+// by the different Firefox channels, in order of precedence.
+var defaultSuffixes = []string{
+	".default-release",     // release
+	".default-esr",         // esr
+	".dev-edition-default", // developer edition
+	".default-nightly",     // nightly
+	".default",             // legacy
+}
+
+// Channel is a Firefox release channel, used to choose the default profile.
+// See [WithChannel].
+type Channel int
+
+// Channel values.
+const (
+	// ChannelAuto chooses the default profile of any channel, in order of
+	// precedence (release, esr, developer edition, nightly, then legacy).
+	ChannelAuto Channel = iota
+	// ChannelRelease is the release channel.
+	ChannelRelease
+	// ChannelESR is the extended support release channel. Profiles created by
+	// older ESR installs use the legacy .default suffix.
+	ChannelESR
+	// ChannelDeveloperEdition is the developer edition channel.
+	ChannelDeveloperEdition
+	// ChannelNightly is the nightly channel.
+	ChannelNightly
+)
+
+// String satisfies the fmt.Stringer interface.
+func (ch Channel) String() string {
+	switch ch {
+	case ChannelAuto:
+		return "auto"
+	case ChannelRelease:
+		return "release"
+	case ChannelESR:
+		return "esr"
+	case ChannelDeveloperEdition:
+		return "developer edition"
+	case ChannelNightly:
+		return "nightly"
+	}
+	return fmt.Sprintf("Channel(%d)", int(ch))
+}
+
+// suffixes returns the directory name suffixes of the channel's default
+// profiles, in order of precedence.
+func (ch Channel) suffixes() []string {
+	switch ch {
+	case ChannelRelease:
+		return []string{".default-release"}
+	case ChannelESR:
+		return []string{".default-esr", ".default"}
+	case ChannelDeveloperEdition:
+		return []string{".dev-edition-default"}
+	case ChannelNightly:
+		return []string{".default-nightly"}
+	}
+	return defaultSuffixes
+}
+
+// matches returns true when the profile path is a default profile of the
+// channel.
+func (ch Channel) matches(path string) bool {
+	if ch == ChannelAuto {
+		return true
+	}
+	return slices.ContainsFunc(ch.suffixes(), func(suffix string) bool {
+		return strings.HasSuffix(filepath.Base(path), suffix)
+	})
+}
+
+// defaultProfile determines the default profile in dir, returning the
+// profile's path and the reason it was chosen.
+//
+// The default profile is determined as Firefox does: the install defaults in
+// installs.ini (or the Install sections of profiles.ini) are used first, then
+// the profile marked as the default in profiles.ini. When there are multiple
+// install defaults (such as when multiple Firefox channels are installed),
+// the channel is chosen in the order of precedence of defaultSuffixes.
+//
+// Otherwise, default profiles are identified by the directory name suffix of
+// each Firefox channel, checked in the order of precedence of defaultSuffixes
+// (release, esr, developer edition, nightly, then legacy). When there are
+// multiple default profiles for a channel, the most recently used one is
+// chosen (see choosePath).
+//
+// When neither profiles.ini nor installs.ini exist, or none of the profiles
+// are a channel's default profile, the most recently used profile is chosen.
+//
+// When a channel has been selected, only the channel's default profiles are
+// considered.
+func (pfs profileFS) defaultProfile(dir string) (string, ProfileReason, error) {
+	profiles, err := pfs.discoverProfiles(dir)
+	switch {
+	case err != nil:
+		return "", "", err
+	case len(profiles) == 0:
+		return "", "", fmt.Errorf("%w: no firefox profiles in %s", ErrProfileNotFound, dir)
+	}
+	// install defaults
+	installs, err := pfs.installDefaults(dir)
+	if err != nil {
+		return "", "", err
+	}
+	if paths := pfs.channelPaths(installs); len(paths) != 0 {
+		return pfs.choosePath(dir, paths, ReasonInstallDefault)
+	}
+	// profiles.ini default
+	for _, p := range profiles {
+		if p.Default && pfs.channel.matches(p.Path) && pfs.isDir(p.Path) {
+			return p.Path, ReasonProfilesDefault, nil
+		}
+	}
+	// without profiles.ini, use the most recently used profile
+	var paths []string
+	for _, p := range profiles {
+		if pfs.channel == ChannelAuto || pfs.channel.matches(p.Path) {
+			paths = append(paths, p.Path)
+		}
+	}
+	if !pfs.hasIni(dir) && len(paths) != 0 {
+		return pfs.choosePath(dir, paths, ReasonMostRecentlyUsed)
+	}
+	// directory name suffixes
+	for _, suffix := range pfs.channel.suffixes() {
+		var paths []string
+		for _, p := range profiles {
+			if strings.HasSuffix(filepath.Base(p.Path), suffix) {
+				paths = append(paths, p.Path)
+			}
+		}
+		if len(paths) != 0 {
+			return pfs.choosePath(dir, paths, ReasonChannelSuffix)
+		}
+	}
+	if pfs.channel != ChannelAuto {
+		return "", "", fmt.Errorf("%w: no default firefox %s profile in %s", ErrProfileNotFound, pfs.channel, dir)
+	}
+	return pfs.choosePath(dir, paths, ReasonMostRecentlyUsed)
+}
+
+// hasIni returns true when profiles.ini or installs.ini exist in dir.
+func (pfs profileFS) hasIni(dir string) bool {
+	for _, name := range []string{"profiles.ini", "installs.ini"} {
+		if _, err := pfs.stat(pfs.join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// channelPaths returns the profile paths for the first channel in
+// defaultSuffixes with a profile in paths. Returns paths when none of the
+// paths are for a channel's default profile, unless a channel has been
+// selected.
+func (pfs profileFS) channelPaths(paths []string) []string {
+	for _, suffix := range pfs.channel.suffixes() {
+		var res []string
+		for _, path := range paths {
+			if strings.HasSuffix(filepath.Base(path), suffix) {
+				res = append(res, path)
+			}
+		}
+		if len(res) != 0 {
+			return res
+		}
+	}
+	if pfs.channel != ChannelAuto {
+		return nil
+	}
+	return paths
+}
+
+// choosePath chooses the default profile from the profile paths, preferring
+// the one marked as the default in the installs.ini or profiles.ini in dir,
+// then the most recently used one (by the modification time of its times.json
+// and prefs.js), and then the one with the most recently modified cookie
+// database. The reason is returned when there is only one path.
+func (pfs profileFS) choosePath(dir string, paths []string, reason ProfileReason) (string, ProfileReason, error) {
+	if len(paths) == 1 {
+		return paths[0], reason, nil
+	}
+	// check installs.ini and profiles.ini
+	installs, err := pfs.installDefaults(dir)
+	if err != nil {
+		return "", "", err
+	}
+	defaults, err := pfs.iniDefaults(dir)
+	switch {
+	case err != nil && !os.IsNotExist(err):
+		return "", "", err
+	case err == nil:
+		for _, path := range defaults {
+			switch {
+			case slices.Contains(paths, path) && slices.Contains(installs, path):
+				return path, ReasonInstallDefault, nil
+			case slices.Contains(paths, path):
+				return path, ReasonProfilesDefault, nil
+			}
+		}
+	}
+	// use most recently used profile
+	if path, ambiguous := pfs.newestUsed(paths); path != "" && !ambiguous {
+		return path, ReasonMostRecentlyUsed, nil
+	}
+	// use most recently modified cookie database
+	path, ambiguous := pfs.newestCookies(paths)
+	if path == "" || ambiguous {
+		return "", "", fmt.Errorf("ambiguous default firefox profile in %s: %s", dir, strings.Join(paths, ", "))
+	}
+	return path, ReasonNewestCookies, nil
+}
+
+// MostRecentProfile returns the name of the Firefox profile with the most
+// recently modified cookie database. Profiles without a cookie database are
+// skipped.
+func MostRecentProfile(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	dir, err := ProfileDir()
+	if err != nil {
+		return "", err
+	}
+	profiles, err := osFS.discoverProfiles(dir)
+	if err != nil {
+		return "", err
+	}
+	var paths []string
+	for _, p := range profiles {
+		paths = append(paths, p.Path)
+	}
+	path, _ := osFS.newestCookies(paths)
+	if i := slices.IndexFunc(profiles, func(p Profile) bool {
+		return p.Path == path
+	}); path != "" && i != -1 {
+		return profiles[i].Name, nil
+	}
+	return "", fmt.Errorf("%w: no firefox profile with a cookie database in %s", ErrCookieDBNotFound, dir)
+}
+
+// newestUsed returns the path of the most recently used profile, by the
+// newest modification time of its times.json and prefs.js, and whether
+// another profile was used at the same time.
+func (pfs profileFS) newestUsed(paths []string) (string, bool) {
+	var path string
+	var newest time.Time
+	ambiguous := false
+	for _, p := range paths {
+		var used time.Time
+		for _, name := range []string{"times.json", "prefs.js"} {
+			if fi, err := pfs.stat(pfs.join(p, name)); err == nil && fi.ModTime().After(used) {
+				used = fi.ModTime()
+			}
+		}
+		switch {
+		case used.IsZero():
+		case used.After(newest):
+			path, newest, ambiguous = p, used, false
+		case used.Equal(newest):
+			ambiguous = true
+		}
+	}
+	return path, ambiguous
+}
+
+// newestCookies returns the path of the profile with the most recently
+// modified cookie database, and whether another profile's cookie database has
+// the same modification time.
+func (pfs profileFS) newestCookies(paths []string) (string, bool) {
+	var path string
+	var newest time.Time
+	ambiguous := false
+	for _, p := range paths {
+		fi, err := pfs.stat(pfs.join(p, "cookies.sqlite"))
+		if err != nil {
+			continue
+		}
+		switch t := fi.ModTime(); {
+		case t.After(newest):
+			path, newest, ambiguous = p, t, false
+		case t.Equal(newest):
+			ambiguous = true
+		}
+	}
+	return path, ambiguous
+}
+
+// iniDefaults returns the paths of the profiles marked as default in
+// installs.ini and profiles.ini in dir. Install defaults are returned first.
+func (pfs profileFS) iniDefaults(dir string) ([]string, error) {
+	installs, err := pfs.installDefaults(dir)
+	if err != nil {
+		return nil, err
+	}
+	sections, err := pfs.readIni(pfs.join(dir, "profiles.ini"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, section := range sections {
+		if strings.HasPrefix(section.Name, "Profile") && section.Keys["Default"] == "1" && section.Keys["Path"] != "" {
+			installs = append(installs, pfs.iniPath(dir, section.Keys["Path"], section.Keys["IsRelative"] != "0"))
+		}
+	}
+	return installs, nil
+}
+
+// installDefaults returns the paths of the existing default profiles of each
+// Firefox install, as listed in installs.ini and the Install sections of
+// profiles.ini in dir.
+func (pfs profileFS) installDefaults(dir string) ([]string, error) {
+	var paths []string
+	for _, name := range []string{"installs.ini", "profiles.ini"} {
+		sections, err := pfs.readIni(pfs.join(dir, name))
+		switch {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		for _, section := range sections {
+			if name == "profiles.ini" && !strings.HasPrefix(section.Name, "Install") {
+				continue
+			}
+			if path := section.Keys["Default"]; path != "" {
+				path = pfs.iniPath(dir, path, !pfs.isAbs(path))
+				if pfs.isDir(path) && !slices.Contains(paths, path) {
+					paths = append(paths, path)
+				}
+			}
+		}
+	}
+	return paths, nil
+}
+
+// isDir returns true when path is a directory.
+func (pfs profileFS) isDir(path string) bool {
+	fi, err := pfs.stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// isFile returns true when path is a regular file.
+func isFile(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// iniPath resolves a profile path from an ini file in dir.
+func (pfs profileFS) iniPath(dir, name string, relative bool) string {
+	switch {
+	case pfs.fsys == nil && relative:
+		return filepath.Join(dir, filepath.FromSlash(name))
+	case pfs.fsys == nil:
+		return filepath.Clean(name)
+	case relative:
+		return path.Join(dir, name)
+	}
+	// absolute paths are relative to the root of the file system
+	return strings.TrimPrefix(path.Clean(name), "/")
+}
+
+// profileFS is the file system profiles are resolved in. The zero value is
+// the local file system. Otherwise, paths are slash-separated paths in fsys.
+type profileFS struct {
+	fsys fs.FS
+	// channel is the channel of the default profile
+	channel Channel
+}
+
+// osFS is the local file system.
+var osFS profileFS
+
+// readIni reads the sections of the ini file.
+func (pfs profileFS) readIni(name string) ([]iniSection, error) {
+	var buf []byte
+	var err error
+	if pfs.fsys != nil {
+		buf, err = fs.ReadFile(pfs.fsys, name)
+	} else {
+		buf, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseIni(string(buf)), nil
+}
+
+// readDir reads the directory entries of the named directory.
+func (pfs profileFS) readDir(name string) ([]fs.DirEntry, error) {
+	if pfs.fsys != nil {
+		return fs.ReadDir(pfs.fsys, name)
+	}
+	return os.ReadDir(name)
+}
+
+// stat returns the file info of the named file.
+func (pfs profileFS) stat(name string) (fs.FileInfo, error) {
+	if pfs.fsys != nil {
+		return fs.Stat(pfs.fsys, name)
+	}
+	return os.Stat(name)
+}
+
+// join joins the path elements.
+func (pfs profileFS) join(elem ...string) string {
+	if pfs.fsys != nil {
+		return path.Join(elem...)
+	}
+	return filepath.Join(elem...)
+}
+
+// isAbs returns true when the path from an ini file is absolute.
+func (pfs profileFS) isAbs(name string) bool {
+	if pfs.fsys != nil {
+		return path.IsAbs(name)
+	}
+	return filepath.IsAbs(name)
+}
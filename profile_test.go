@@ -0,0 +1,251 @@
+package ffcookies
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveProfileMultipleDefaultRelease(t *testing.T) {
+	older, newer := testNow, testNow.Add(time.Hour)
+	tests := []struct {
+		name   string
+		ini    string
+		mtimes []time.Time
+		exp    string
+		reason ProfileReason
+	}{
+		{"newest cookies", "", []time.Time{older, newer}, "b.default-release", ReasonNewestCookies},
+		{"newest cookies first", "", []time.Time{newer, older}, "a.default-release", ReasonNewestCookies},
+		{"ambiguous", "", []time.Time{older, older}, "", ""},
+		{"profiles.ini default", profilesIni([]string{"a.default-release", "b.default-release"}, 0), []time.Time{older, newer}, "a.default-release", ReasonProfilesDefault},
+		{"profiles.ini without default", profilesIni([]string{"a.default-release", "b.default-release"}, -1), []time.Time{newer, older}, "a.default-release", ReasonNewestCookies},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for i, name := range []string{"a.default-release", "b.default-release"} {
+				mkProfile(t, filepath.Join(dir, name), test.mtimes[i])
+			}
+			if test.ini != "" {
+				writeFile(t, filepath.Join(dir, "profiles.ini"), test.ini)
+			}
+			res, err := ResolveProfile(context.Background(), "", WithProfileDir(dir), WithEnv(false))
+			switch {
+			case test.exp == "" && err == nil:
+				t.Fatalf("expected error, got: %s", res.Path)
+			case test.exp == "":
+				return
+			case err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if exp := filepath.Join(dir, test.exp); res.Path != exp {
+				t.Errorf("expected %s, got: %s", exp, res.Path)
+			}
+			if res.Reason != test.reason {
+				t.Errorf("expected reason %q, got: %q", test.reason, res.Reason)
+			}
+		})
+	}
+}
+
+// profilesIni returns a profiles.ini listing the relative profile paths, with
+// the profile at index def marked as the default.
+func profilesIni(paths []string, def int) string {
+	var s string
+	for i, path := range paths {
+		s += "[Profile" + strconv.Itoa(i) + "]\nName=" + filepath.Base(path) + "\nIsRelative=1\nPath=" + path + "\n"
+		if i == def {
+			s += "Default=1\n"
+		}
+		s += "\n"
+	}
+	return s
+}
+
+// mkProfile creates a profile directory with a copy of the test cookie
+// database, modified at mtime.
+func mkProfile(t *testing.T, dir string, mtime time.Time) {
+	t.Helper()
+	buf, err := os.ReadFile(testDB)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	file := filepath.Join(dir, "cookies.sqlite")
+	writeFile(t, file, string(buf))
+	if err := os.Chtimes(file, mtime, mtime); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+// writeFile writes the file, creating its directory.
+func writeFile(t *testing.T, file, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+func TestMostRecentProfile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("APPDATA", home)
+	dirs, err := Firefox.ProfileDirs()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	dir := dirs[0]
+	// without a cookie database
+	writeFile(t, filepath.Join(dir, "d.empty", "prefs.js"), "")
+	if _, err := MostRecentProfile(context.Background()); !errors.Is(err, ErrCookieDBNotFound) {
+		t.Fatalf("expected cookie database not found error, got: %v", err)
+	}
+	mkProfile(t, filepath.Join(dir, "a.default-release"), testNow)
+	mkProfile(t, filepath.Join(dir, "b.work"), testNow.Add(time.Hour))
+	mkProfile(t, filepath.Join(dir, "c.old"), testNow.Add(-time.Hour))
+	profile, err := MostRecentProfile(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if exp := "b.work"; profile != exp {
+		t.Errorf("expected %q, got: %q", exp, profile)
+	}
+	// profile names from profiles.ini
+	writeFile(t, filepath.Join(dir, "profiles.ini"), "[Profile0]\nName=work\nIsRelative=1\nPath=b.work\n")
+	profile, err = MostRecentProfile(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if exp := "work"; profile != exp {
+		t.Errorf("expected %q, got: %q", exp, profile)
+	}
+}
+
+func TestResolveProfileMissingDir(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+	tests := []struct {
+		name string
+		env  string
+		opts []Option
+		err  error
+	}{
+		{"profile dir", "", []Option{WithProfileDir(missing)}, ErrProfileDirNotFound},
+		{"env", missing, nil, ErrProfileDirNotFound},
+		{"search paths", "", []Option{WithSearchPaths(missing)}, ErrProfileDirNotFound},
+		{"home", "", nil, ErrProfileDirNotFound},
+		{"empty profile dir", "", []Option{WithProfileDir(dir)}, ErrProfileNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("HOME", dir)
+			t.Setenv("APPDATA", dir)
+			t.Setenv("LOCALAPPDATA", dir)
+			t.Setenv(EnvProfileDir, test.env)
+			res, err := ResolveProfile(context.Background(), "", test.opts...)
+			switch {
+			case err == nil:
+				t.Fatalf("expected error, got: %s", res.Path)
+			case !errors.Is(err, test.err):
+				t.Fatalf("expected %v, got: %v", test.err, err)
+			}
+			if test.err == ErrProfileDirNotFound && !strings.Contains(err.Error(), dir) {
+				t.Errorf("expected error to contain %s, got: %v", dir, err)
+			}
+		})
+	}
+}
+
+func TestListProfilesAbsolutePath(t *testing.T) {
+	home, external := t.TempDir(), filepath.Join(t.TempDir(), "external")
+	t.Setenv("HOME", home)
+	t.Setenv("APPDATA", home)
+	t.Setenv(EnvProfileDir, "")
+	t.Setenv(EnvProfile, "")
+	dirs, err := Firefox.ProfileDirs()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	dir := dirs[0]
+	mkProfile(t, filepath.Join(dir, "a.local"), testNow)
+	mkProfile(t, external, testNow)
+	writeFile(t, filepath.Join(dir, "profiles.ini"), "[Profile0]\nName=local\nIsRelative=1\nPath=a.local\n\n"+
+		"[Profile1]\nName=external\nIsRelative=0\nPath="+external+"\nDefault=1\n")
+	profiles, err := ListProfiles()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := []Profile{
+		{Name: "local", Path: filepath.Join(dir, "a.local"), IsRelative: true},
+		{Name: "external", Path: external, Default: true},
+	}
+	if !slices.Equal(profiles, exp) {
+		t.Errorf("expected %+v, got: %+v", exp, profiles)
+	}
+	for _, profile := range []string{"external", ""} {
+		res, err := ResolveProfile(context.Background(), profile)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if res.Path != external {
+			t.Errorf("expected %q to resolve to %s, got: %s", profile, external, res.Path)
+		}
+	}
+	cookies, err := Read("external", "accessed.test")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cookies) != 2 {
+		t.Errorf("expected 2 cookies, got: %d", len(cookies))
+	}
+}
+
+func TestResolveProfileChannelSuffixes(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles []string
+		ini      bool
+		opts     []Option
+		exp      string
+	}{
+		{"dev edition only", []string{"a.dev-edition-default"}, false, nil, "a.dev-edition-default"},
+		{"dev edition only with ini", []string{"a.dev-edition-default"}, true, nil, "a.dev-edition-default"},
+		{"nightly only", []string{"a.default-nightly"}, true, nil, "a.default-nightly"},
+		{"dev edition over legacy", []string{"a.default", "b.dev-edition-default"}, true, nil, "b.dev-edition-default"},
+		{"release over esr", []string{"a.default-esr", "b.default-release"}, true, nil, "b.default-release"},
+		{"esr channel", []string{"a.default", "b.default-release"}, true, []Option{WithChannel(ChannelESR)}, "a.default"},
+		{"release channel", []string{"a.dev-edition-default"}, true, []Option{WithChannel(ChannelRelease)}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range test.profiles {
+				mkProfile(t, filepath.Join(dir, name), testNow)
+			}
+			if test.ini {
+				writeFile(t, filepath.Join(dir, "profiles.ini"), profilesIni(test.profiles, -1))
+			}
+			res, err := ResolveProfile(context.Background(), "", append([]Option{WithProfileDir(dir), WithEnv(false)}, test.opts...)...)
+			switch {
+			case test.exp == "" && !errors.Is(err, ErrProfileNotFound):
+				t.Fatalf("expected profile not found error, got: %v", err)
+			case test.exp == "":
+				return
+			case err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if exp := filepath.Join(dir, test.exp); res.Path != exp {
+				t.Errorf("expected %s, got: %s", exp, res.Path)
+			}
+		})
+	}
+}
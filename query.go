@@ -0,0 +1,132 @@
+package ffcookies
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kenshaw/ffcookies/models"
+)
+
+// query builds the where clause for a cookie query, and the equivalent
+// matching funcs used when reading without a sqlite3 driver.
+type query struct {
+	conds   []string
+	args    []any
+	matches []func(*models.Cookie) bool
+}
+
+// arg adds a query argument, returning its positional placeholder.
+func (q *query) arg(v any) string {
+	q.args = append(q.args, v)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// and adds a condition to the query, and the func matching the same cookies
+// as the condition.
+func (q *query) and(cond string, match func(*models.Cookie) bool) {
+	q.conds = append(q.conds, cond)
+	q.matches = append(q.matches, match)
+}
+
+// where returns the where clause.
+func (q *query) where() string {
+	return strings.Join(q.conds, " AND ")
+}
+
+// match returns true when the cookie matches all of the query's conditions.
+func (q *query) match(c *models.Cookie) bool {
+	for _, match := range q.matches {
+		if !match(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// originAttr adds a condition matching the cookies whose origin attributes
+// have (or, when has is false, do not have) the key with value. An empty value
+// matches any value.
+//
+// The leading ^ of the origin attributes is replaced with &, so that the
+// attribute is matched regardless of its position.
+func (q *query) originAttr(key, value string, has bool) {
+	pattern := "%&" + key + "=" + value + "&%"
+	if value == "" {
+		pattern = "%&" + key + "=%"
+	}
+	cond := "LIKE"
+	if !has {
+		cond = "NOT LIKE"
+	}
+	q.and("('&' || substr(originAttributes, 2) || '&') "+cond+" "+q.arg(pattern), func(c *models.Cookie) bool {
+		attrs := c.OriginAttributes
+		if attrs != "" {
+			attrs = attrs[1:]
+		}
+		return like(pattern, "&"+attrs+"&") == has
+	})
+}
+
+// escapeLike escapes the LIKE metacharacters in s, for use in a LIKE pattern
+// with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// like returns true when s matches the pattern, as with the sqlite3 LIKE
+// operator with ESCAPE '\': % matches any sequence of characters, _ matches
+// any single character, \ escapes the following character, and ASCII
+// characters are matched case insensitively. Characters are matched by rune,
+// not by byte.
+func like(pattern, s string) bool {
+	var p, i int
+	nextP, nextI := -1, -1
+	for i < len(s) || p < len(pattern) {
+		_, n := utf8.DecodeRuneInString(s[i:])
+		if p < len(pattern) {
+			switch c, m := utf8.DecodeRuneInString(pattern[p:]); {
+			case c == '\\' && p+1 < len(pattern):
+				_, k := utf8.DecodeRuneInString(pattern[p+1:])
+				if i < len(s) && equalFold(pattern[p+1:p+1+k], s[i:i+n]) {
+					p, i = p+1+k, i+n
+					continue
+				}
+			case c == '%':
+				nextP, nextI = p, i+max(n, 1)
+				p++
+				continue
+			case c == '_':
+				if i < len(s) {
+					p, i = p+1, i+n
+					continue
+				}
+			default:
+				if i < len(s) && equalFold(pattern[p:p+m], s[i:i+n]) {
+					p, i = p+m, i+n
+					continue
+				}
+			}
+		}
+		if nextI > 0 && nextI <= len(s) {
+			p, i = nextP, nextI
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// equalFold returns true when the characters a and b are equal, comparing
+// ASCII characters case insensitively.
+func equalFold(a, b string) bool {
+	return a == b || len(a) == 1 && len(b) == 1 && lower(a[0]) == lower(b[0])
+}
+
+// lower returns the lower case of an ASCII character.
+func lower(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
@@ -0,0 +1,43 @@
+package ffcookies
+
+import "testing"
+
+func TestLike(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		exp     bool
+	}{
+		{"%example.com", "www.example.com", true},
+		{"%EXAMPLE.com", "www.example.com", true},
+		{"%example.com", "example.org", false},
+		{"%\\_b", "a_b", true},
+		{"%\\_b", "axb", false},
+		{"%\\%b", "a%b", true},
+		{"_", "é", true},
+		{"__", "é", false},
+		{"%_", "é", true},
+		{"%__", "é", false},
+		{"%_b", "éb", true},
+		{"_b", "éb", true},
+		{"a_c", "aéc", true},
+		{"a_c", "aéxc", false},
+		{"%ü%", "bücher.example", true},
+		{"%\\ü%", "bücher.example", true},
+		{"%Ü%", "bücher.example", false},
+		{"%.café", "www.café", true},
+		{"%é", "e", false},
+		{"\xff", "\xfe", false},
+		{"_\xff", "\xfe\xff", true},
+		{"", "", true},
+		{"%", "", true},
+		{"_", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.s, func(t *testing.T) {
+			if v := like(test.pattern, test.s); v != test.exp {
+				t.Errorf("expected %t, got: %t", test.exp, v)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package ffcookies
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reader reads and caches the cookies of a Firefox profile by host. A Reader
+// is safe for concurrent use.
+//
+// The Reader keeps the cookie database open between reads. As the database is
+// opened as an immutable snapshot, the database is reopened (and the cached
+// cookies discarded) when the cookie database's modification time changes.
+//
+// Temporary files created while reading (such as copies of the cookie
+// database) are kept until the Reader is closed.
+type Reader struct {
+	profile string
+	opts    []Option
+	o       *options
+	mu      sync.Mutex
+	cache   map[string][]*http.Cookie
+	path    string
+	src     source
+	close   func() error
+	modTime time.Time
+	tempMu  sync.Mutex
+	temp    string
+}
+
+// NewReader creates a cookie reader for the provided Firefox profile name, or
+// the default Firefox profile. The reader should be closed when no longer
+// needed.
+func NewReader(profile string, opts ...Option) *Reader {
+	r := &Reader{
+		profile: profile,
+		cache:   make(map[string][]*http.Cookie),
+	}
+	r.opts = append(slices.Clone(opts), func(o *options) {
+		o.tempDir = r.tempDir
+	})
+	r.o = newOptions(r.opts...)
+	return r
+}
+
+// Cookies returns the cookies for the host, reading them on first use.
+//
+// The returned cookies are copies of the cached cookies, and can be modified
+// without affecting subsequent calls.
+func (r *Reader) Cookies(ctx context.Context, host string) ([]*http.Cookie, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.open(ctx); err != nil {
+		return nil, err
+	}
+	cookies, ok := r.cache[host]
+	if !ok {
+		var err error
+		if cookies, err = r.o.read(ctx, r.src, r.path, host); err != nil {
+			return nil, err
+		}
+		r.cache[host] = cookies
+	}
+	return copyCookies(cookies), nil
+}
+
+// CookiesForURL returns the cookies that Firefox would send in a top-level
+// request to the url, reading the cookies for the url's scheme and host on
+// first use. See [CookiesForURL].
+//
+// The returned cookies are copies of the cached cookies, and can be modified
+// without affecting subsequent calls.
+func (r *Reader) CookiesForURL(ctx context.Context, u *url.URL) ([]*http.Cookie, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.open(ctx); err != nil {
+		return nil, err
+	}
+	key := siteScheme(strings.ToLower(u.Scheme)) + "://" + strings.ToLower(u.Hostname())
+	cookies, ok := r.cache[key]
+	if !ok {
+		o := *r.o
+		urlOption(u)(&o)
+		var err error
+		if cookies, err = o.read(ctx, r.src, r.path, ""); err != nil {
+			return nil, err
+		}
+		r.cache[key] = cookies
+	}
+	return copyCookies(filterURL(u, cookies)), nil
+}
+
+// open opens the cookie database, reopening it when its modification time has
+// changed.
+func (r *Reader) open(ctx context.Context) error {
+	if r.path == "" {
+		var err error
+		if r.path, err = r.o.cookiePath(r.profile); err != nil {
+			return err
+		}
+	}
+	fi, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+	modTime := fi.ModTime()
+	if r.o.live {
+		// the write-ahead log changes without the database changing
+		if fi, err := os.Stat(r.path + "-wal"); err == nil && fi.ModTime().After(modTime) {
+			modTime = fi.ModTime()
+		}
+	}
+	if r.src != nil && modTime.Equal(r.modTime) {
+		return nil
+	}
+	if err := r.closeDB(); err != nil {
+		return err
+	}
+	if r.src, r.close, err = r.o.open(ctx, fileURI(r.path, r.o.openParams)); err != nil {
+		return err
+	}
+	r.modTime = modTime
+	return nil
+}
+
+// closeDB closes the cookie database, discarding the cached cookies.
+func (r *Reader) closeDB() error {
+	clear(r.cache)
+	if r.src == nil {
+		return nil
+	}
+	err := r.close()
+	r.src, r.close = nil, nil
+	return err
+}
+
+// Reset clears the cached cookies.
+func (r *Reader) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.cache)
+}
+
+// Close closes the reader, closing the cookie database, clearing the cached
+// cookies and removing any temporary files created by the reader.
+func (r *Reader) Close() error {
+	r.mu.Lock()
+	err := r.closeDB()
+	r.mu.Unlock()
+	r.tempMu.Lock()
+	defer r.tempMu.Unlock()
+	if r.temp != "" {
+		if e := os.RemoveAll(r.temp); err == nil {
+			err = e
+		}
+		r.temp = ""
+	}
+	return err
+}
+
+// tempDir returns the reader's temporary directory, creating it if needed.
+func (r *Reader) tempDir() (string, error) {
+	r.tempMu.Lock()
+	defer r.tempMu.Unlock()
+	if r.temp == "" {
+		var err error
+		if r.temp, err = os.MkdirTemp("", "ffcookies-"); err != nil {
+			return "", err
+		}
+	}
+	return r.temp, nil
+}
+
+// copyCookies returns a deep copy of the cookies.
+func copyCookies(cookies []*http.Cookie) []*http.Cookie {
+	if cookies == nil {
+		return nil
+	}
+	res := make([]*http.Cookie, len(cookies))
+	for i, cookie := range cookies {
+		c := *cookie
+		c.Unparsed = slices.Clone(cookie.Unparsed)
+		res[i] = &c
+	}
+	return res
+}
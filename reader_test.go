@@ -0,0 +1,159 @@
+package ffcookies
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReaderCookiesCopy(t *testing.T) {
+	r := NewReader("", WithCookieFile(testDB), WithMetadata())
+	defer r.Close()
+	exp := map[string]string{"new": "2", "old": "1"}
+	cookies, err := r.Cookies(context.Background(), "accessed.test")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if values := cookieValues(cookies); !maps.Equal(values, exp) {
+		t.Fatalf("expected %v, got: %v", exp, values)
+	}
+	for _, cookie := range cookies {
+		cookie.Value = "modified"
+		cookie.Unparsed[0] = "modified"
+	}
+	cookies[0] = nil
+	cookies, err = r.Cookies(context.Background(), "accessed.test")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if values := cookieValues(cookies); !maps.Equal(values, exp) {
+		t.Errorf("expected %v, got: %v", exp, values)
+	}
+	for _, cookie := range cookies {
+		if cookie.Unparsed[0] == "modified" {
+			t.Errorf("expected unmodified metadata, got: %v", cookie.Unparsed)
+		}
+	}
+}
+
+func TestReaderCookiesForURLCopy(t *testing.T) {
+	r := NewReader("", WithCookieFile(testDB))
+	defer r.Close()
+	u, err := url.Parse("https://hostonly.test/")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := map[string]string{"domain": "2", "host": "1"}
+	for range 2 {
+		cookies, err := r.CookiesForURL(context.Background(), u)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if values := cookieValues(cookies); !maps.Equal(values, exp) {
+			t.Fatalf("expected %v, got: %v", exp, values)
+		}
+		for _, cookie := range cookies {
+			cookie.Value = "modified"
+		}
+	}
+}
+
+func TestReaderCloseRemovesTemp(t *testing.T) {
+	r := NewReader("", WithCookieFile(testDB))
+	// temporary files are kept between reads
+	dir, cleanup, err := r.o.mkTemp()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	file := filepath.Join(dir, "cookies.sqlite")
+	if err := os.WriteFile(file, []byte("copy"), 0o600); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cleanup()
+	if _, err := os.Stat(file); err != nil {
+		t.Fatalf("expected temporary file to be kept, got: %v", err)
+	}
+	if d, _, err := r.o.mkTemp(); err != nil || d != dir {
+		t.Fatalf("expected temporary directory %s to be reused, got: %s %v", dir, d, err)
+	}
+	if _, err := r.Cookies(context.Background(), "accessed.test"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := os.Stat(dir); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected temporary directory to be removed, got: %v", err)
+	}
+	// closing again
+	if err := r.Close(); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestReaderReopen(t *testing.T) {
+	buf, err := os.ReadFile(testDB)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "cookies.sqlite")
+	write := func(buf []byte, mtime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(file, buf, 0o644); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if err := os.Chtimes(file, mtime, mtime); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	value := func(r *Reader) string {
+		t.Helper()
+		cookies, err := r.Cookies(context.Background(), "decode.test")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		return cookieValues(cookies)["double"]
+	}
+	write(buf, testNow)
+	r := NewReader("", WithCookieFile(file))
+	defer r.Close()
+	if v, exp := value(r), "a%2520b"; v != exp {
+		t.Fatalf("expected %q, got: %q", exp, v)
+	}
+	src := r.src
+	// same modification time
+	if v, exp := value(r), "a%2520b"; v != exp || r.src != src {
+		t.Fatalf("expected %q from the same database, got: %q", exp, v)
+	}
+	// change the value in place, keeping the modification time
+	if bytes.Count(buf, []byte("a%2520b")) != 1 {
+		t.Fatalf("expected value to be in the database once")
+	}
+	buf = bytes.Replace(buf, []byte("a%2520b"), []byte("a%2521b"), 1)
+	write(buf, testNow)
+	if v, exp := value(r), "a%2520b"; v != exp {
+		t.Errorf("expected cached %q, got: %q", exp, v)
+	}
+	// changed modification time
+	write(buf, testNow.Add(time.Second))
+	if v, exp := value(r), "a%2521b"; v != exp {
+		t.Errorf("expected %q after reopening, got: %q", exp, v)
+	}
+	if r.src == src {
+		t.Errorf("expected database to be reopened")
+	}
+	// close
+	if err := r.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if r.src != nil || r.close != nil || len(r.cache) != 0 {
+		t.Errorf("expected database to be closed and cache cleared")
+	}
+}
@@ -0,0 +1,112 @@
+package ffcookies
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// SourceFactory creates a cookie source for a profile, or for the default
+// profile when profile is empty. See [RegisterSource].
+type SourceFactory func(profile string) (CookieSource, error)
+
+// sources are the registered cookie sources.
+var sources = struct {
+	sync.RWMutex
+	factories map[string]SourceFactory
+}{
+	factories: make(map[string]SourceFactory),
+}
+
+func init() {
+	for name, b := range map[string]Browser{
+		"firefox":     Firefox,
+		"tor":         TorBrowser,
+		"librewolf":   LibreWolf,
+		"waterfox":    Waterfox,
+		"floorp":      Floorp,
+		"mullvad":     MullvadBrowser,
+		"thunderbird": Thunderbird,
+		"seamonkey":   SeaMonkey,
+		"palemoon":    PaleMoon,
+	} {
+		RegisterSource(name, func(profile string) (CookieSource, error) {
+			return FirefoxSource{
+				Profile: profile,
+				Options: []Option{WithBrowser(b)},
+			}, nil
+		})
+	}
+}
+
+// RegisterSource registers a cookie source by name, similar to database/sql
+// drivers, so that packages can contribute cookie sources for other browsers
+// that can be selected by name. Panics when the factory is nil, or when
+// called twice for the same name.
+//
+// A registered cookie source is selected when the profile provided to a read
+// (such as with [ReadContext], [ReadJar] or [WithProfile]) is prefixed with
+// its name and a colon, followed by the profile to read, or nothing for the
+// default profile (for example, "librewolf:work" or "librewolf:"), unless a
+// cookie source has been set with [WithSource]. As Firefox does not allow
+// colons in profile names, profile names are never mistaken for a cookie
+// source.
+//
+// The Firefox based browsers are registered by default as "firefox", "tor",
+// "librewolf", "waterfox", "floorp", "mullvad", "thunderbird", "seamonkey"
+// and "palemoon".
+func RegisterSource(name string, factory SourceFactory) {
+	sources.Lock()
+	defer sources.Unlock()
+	if factory == nil {
+		panic("ffcookies: RegisterSource factory is nil")
+	}
+	if _, ok := sources.factories[name]; ok {
+		panic("ffcookies: RegisterSource called twice for source " + name)
+	}
+	sources.factories[name] = factory
+}
+
+// Sources returns the sorted names of the registered cookie sources.
+func Sources() []string {
+	sources.RLock()
+	defer sources.RUnlock()
+	names := make([]string, 0, len(sources.factories))
+	for name := range sources.factories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// OpenSource creates the registered cookie source for the profile, or the
+// default profile when profile is empty.
+func OpenSource(name, profile string) (CookieSource, error) {
+	sources.RLock()
+	factory, ok := sources.factories[name]
+	sources.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSource, name)
+	}
+	return factory(profile)
+}
+
+// selectSource selects the registered cookie source named by the profile's
+// prefix (see [RegisterSource]), when no cookie source has been set.
+func (o *options) selectSource() error {
+	if o.source != nil || filepath.IsAbs(o.profile) || filepath.VolumeName(o.profile) != "" {
+		return nil
+	}
+	name, profile, ok := strings.Cut(o.profile, ":")
+	if !ok {
+		return nil
+	}
+	src, err := OpenSource(name, profile)
+	if err != nil {
+		return err
+	}
+	o.source, o.profile = src, profile
+	return nil
+}
@@ -0,0 +1,88 @@
+package ffcookies
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// RetryPolicy is the policy for retrying reads that fail because the cookie
+// database is busy or locked, such as while Firefox checkpoints the database.
+// Waits between attempts increase exponentially.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts. Retries are disabled when
+	// less than 2.
+	Attempts int
+	// Wait is the wait before the first retry, which is doubled after each
+	// retry.
+	Wait time.Duration
+	// MaxWait is the maximum wait between attempts. No maximum when 0.
+	MaxWait time.Duration
+	// Jitter is the fraction (0 to 1) of each wait that is randomized.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is a retry policy suitable for reading a database in use
+// by a running Firefox.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts: 5,
+	Wait:     50 * time.Millisecond,
+	MaxWait:  2 * time.Second,
+	Jitter:   0.2,
+}
+
+// wait returns the wait before retry n (starting at 0).
+func (policy RetryPolicy) wait(n int) time.Duration {
+	d := policy.Wait
+	for range n {
+		if d *= 2; policy.MaxWait > 0 && d >= policy.MaxWait {
+			break
+		}
+	}
+	if policy.MaxWait > 0 {
+		d = min(d, policy.MaxWait)
+	}
+	if j := min(max(policy.Jitter, 0), 1); j > 0 {
+		d = time.Duration(float64(d) * (1 - j + 2*j*rand.Float64()))
+	}
+	return d
+}
+
+// retry calls f until it succeeds, fails with an error other than a busy
+// error, or the retry policy's attempts are exhausted.
+func (o *options) retry(ctx context.Context, f func() error) error {
+	policy := o.retries()
+	for n := 0; ; n++ {
+		err := f()
+		if err == nil || !isBusy(err) || n+1 >= policy.Attempts {
+			return err
+		}
+		d := policy.wait(n)
+		o.logger.Debug("database busy, retrying", "attempt", n+1, "wait", d, "error", err)
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
+
+// isBusy returns true when the error is a sqlite3 busy or locked error.
+func isBusy(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{
+		"database is locked",
+		"database table is locked",
+		"database is busy",
+		"sqlite_busy",
+		"sqlite_locked",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package ffcookies
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// ScanResult is the result of scanning a profile. See [ScanSystem].
+type ScanResult struct {
+	// User is the user, the base name of the user's home directory.
+	User string
+	// Home is the user's home directory.
+	Home string
+	// Browser is the browser.
+	Browser Browser
+	// Dir is the base profile directory.
+	Dir string
+	// Profile is the profile, or the zero value when the base profile
+	// directory could not be read.
+	Profile Profile
+	// Cookies are the profile's cookies.
+	Cookies []*http.Cookie
+	// Err is the error reading the base profile directory, or the profile's
+	// cookies.
+	Err error
+}
+
+// browsers are the browsers scanned by ScanSystem.
+var browsers = []Browser{
+	Firefox,
+	TorBrowser,
+	LibreWolf,
+	Waterfox,
+	Floorp,
+	MullvadBrowser,
+	Thunderbird,
+	SeaMonkey,
+	PaleMoon,
+}
+
+// ScanSystem discovers the profiles with a cookie database of every
+// [Browser] for all users on the system, and reads each profile's cookies,
+// returning a result per profile. Users are the directories in the users
+// directory (see [WithUsersDir]), and root's home directory on platforms
+// other than Windows and macOS.
+//
+// Errors reading a user's base profile directory (such as when permission is
+// denied), or a profile's cookies, are returned on the result, and do not
+// stop the scan. Profiles are read concurrently (see [WithConcurrency]).
+func ScanSystem(ctx context.Context, opts ...Option) ([]*ScanResult, error) {
+	o := newOptions(opts...)
+	homes, err := o.homeDirs()
+	if err != nil {
+		return nil, err
+	}
+	var res []*ScanResult
+	var paths []string
+	profiles := make(map[string]*ScanResult)
+	for _, home := range homes {
+		for _, b := range browsers {
+			dirs, err := b.dirs(func(elem string) (string, error) {
+				switch elem {
+				case appData:
+					return filepath.Join(home, "AppData", "Roaming"), nil
+				case localAppData:
+					return filepath.Join(home, "AppData", "Local"), nil
+				}
+				return home, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, dir := range dirs {
+				r := ScanResult{
+					User:    filepath.Base(home),
+					Home:    home,
+					Browser: b,
+					Dir:     dir,
+				}
+				switch fi, err := os.Stat(dir); {
+				case os.IsNotExist(err):
+					continue
+				case err != nil:
+					r.Err = err
+					res = append(res, &r)
+					continue
+				case !fi.IsDir():
+					continue
+				}
+				discovered, err := osFS.discoverProfiles(dir)
+				if err != nil {
+					r.Err = err
+					res = append(res, &r)
+					continue
+				}
+				for _, p := range discovered {
+					if profiles[p.Path] != nil {
+						continue
+					}
+					r := r
+					r.Profile = p
+					switch _, err := os.Stat(filepath.Join(p.Path, "cookies.sqlite")); {
+					case os.IsNotExist(err):
+						continue
+					case err != nil:
+						r.Err = err
+					default:
+						paths = append(paths, p.Path)
+					}
+					profiles[p.Path] = &r
+					res = append(res, &r)
+				}
+			}
+		}
+	}
+	// read profiles
+	for pr := range ReadParallel(ctx, paths, "", opts...) {
+		profiles[pr.Profile].Cookies, profiles[pr.Profile].Err = pr.Cookies, pr.Err
+	}
+	if err := ctx.Err(); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// homeDirs returns the home directories of the users on the system.
+func (o *options) homeDirs() ([]string, error) {
+	usersDir, root := o.usersDir, ""
+	if usersDir == "" {
+		switch runtime.GOOS {
+		case "windows":
+			drive := os.Getenv("SystemDrive")
+			if drive == "" {
+				drive = "C:"
+			}
+			usersDir = drive + `\Users`
+		case "darwin":
+			usersDir = "/Users"
+		default:
+			usersDir, root = "/home", "/root"
+		}
+	}
+	entries, err := os.ReadDir(usersDir)
+	if err != nil {
+		return nil, err
+	}
+	var homes []string
+	for _, entry := range entries {
+		// follow symlinks
+		if dir := filepath.Join(usersDir, entry.Name()); osFS.isDir(dir) {
+			homes = append(homes, dir)
+		}
+	}
+	if root != "" && osFS.isDir(root) {
+		homes = append(homes, root)
+	}
+	return homes, nil
+}
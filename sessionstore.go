@@ -0,0 +1,213 @@
+package ffcookies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"iter"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/kenshaw/ffcookies/internal/mozlz4"
+	"github.com/kenshaw/ffcookies/models"
+)
+
+// sessionStoreFiles are the session store files in a profile directory.
+// Firefox writes the recovery files while running, and sessionstore.jsonlz4
+// on shutdown.
+var sessionStoreFiles = []string{
+	filepath.Join("sessionstore-backups", "recovery.jsonlz4"),
+	filepath.Join("sessionstore-backups", "recovery.baklz4"),
+	"sessionstore.jsonlz4",
+}
+
+// ReadSessionStore reads the session cookies for the host from the session
+// store of the provided Firefox profile name, or the default Firefox profile.
+// While Firefox is running, session cookies (cookies without an expiry) may
+// only be stored in the session store, and not in the cookie database. See
+// [WithSessionStore] to merge the session cookies into the cookies read from
+// the cookie database.
+//
+// The most recently modified session store file is read (the
+// sessionstore-backups/recovery.jsonlz4 file written while Firefox is
+// running, its backup, or the sessionstore.jsonlz4 file written on shutdown).
+// Session store files are mozlz4 compressed JSON.
+func ReadSessionStore(ctx context.Context, profile, host string, opts ...Option) ([]*http.Cookie, error) {
+	o := newOptions(opts...)
+	cookiePath, err := o.cookiePath(profile)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.resolveContainer(nil, cookiePath); err != nil {
+		return nil, err
+	}
+	session, err := o.readSessionStore(filepath.Dir(cookiePath))
+	if err != nil {
+		return nil, err
+	}
+	return o.read(ctx, session, "", host)
+}
+
+// readSessionStore reads the session cookies from the most recently modified
+// session store file in the profile directory.
+func (o *options) readSessionStore(dir string) (sessionSource, error) {
+	var name string
+	var newest time.Time
+	for _, file := range sessionStoreFiles {
+		if fi, err := os.Stat(filepath.Join(dir, file)); err == nil && fi.ModTime().After(newest) {
+			name, newest = filepath.Join(dir, file), fi.ModTime()
+		}
+	}
+	if name == "" {
+		return nil, &fs.PathError{Op: "open", Path: filepath.Join(dir, sessionStoreFiles[0]), Err: fs.ErrNotExist}
+	}
+	o.logger.Debug("reading session store", "file", name)
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if buf, err = mozlz4.Decode(buf); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	var v struct {
+		Cookies []sessionCookie `json:"cookies"`
+		// cookies were stored per window by older Firefox versions
+		Windows []struct {
+			Cookies []sessionCookie `json:"cookies"`
+		} `json:"windows"`
+	}
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	cookies := v.Cookies
+	for _, w := range v.Windows {
+		cookies = append(cookies, w.Cookies...)
+	}
+	var src sessionSource
+	for _, c := range cookies {
+		src = append(src, c.cookie())
+	}
+	return src, nil
+}
+
+// sessionCookie is a cookie in the session store.
+type sessionCookie struct {
+	Host             string         `json:"host"`
+	Value            string         `json:"value"`
+	Path             string         `json:"path"`
+	Name             string         `json:"name"`
+	Secure           bool           `json:"secure"`
+	HTTPOnly         bool           `json:"httponly"`
+	Expiry           int64          `json:"expiry"`
+	OriginAttributes map[string]any `json:"originAttributes"`
+	SameSite         int            `json:"sameSite"`
+	SchemeMap        int            `json:"schemeMap"`
+}
+
+// cookie converts the session store cookie to a cookie.
+func (c sessionCookie) cookie() *models.Cookie {
+	return &models.Cookie{
+		Expiry:           c.Expiry,
+		Host:             c.Host,
+		Name:             c.Name,
+		Value:            c.Value,
+		Path:             c.Path,
+		IsSecure:         c.Secure,
+		IsHTTPOnly:       c.HTTPOnly,
+		SameSite:         models.SameSite(c.SameSite),
+		RawSameSite:      models.SameSite(c.SameSite),
+		OriginAttributes: originSuffix(c.OriginAttributes),
+		SchemeMap:        models.SchemeMap(c.SchemeMap),
+	}
+}
+
+// originSuffix returns the origin attributes suffix (as stored in the
+// originAttributes column) for the origin attributes of a session store
+// cookie. Attributes with default values are omitted.
+func originSuffix(attrs map[string]any) string {
+	var s string
+	for _, key := range []string{
+		"userContextId",
+		"privateBrowsingId",
+		"firstPartyDomain",
+		"geckoViewSessionContextId",
+		"partitionKey",
+	} {
+		var value string
+		switch v := attrs[key].(type) {
+		case float64:
+			if v != 0 {
+				value = strconv.FormatInt(int64(v), 10)
+			}
+		case string:
+			value = url.QueryEscape(v)
+		}
+		if value == "" {
+			continue
+		}
+		if s == "" {
+			s = "^"
+		} else {
+			s += "&"
+		}
+		s += key + "=" + value
+	}
+	return s
+}
+
+// sessionSource is a cookie source for the cookies in a session store. The
+// query is evaluated using its matching funcs.
+type sessionSource []*models.Cookie
+
+// cookies satisfies the source interface.
+func (src sessionSource) cookies(ctx context.Context, q *query) iter.Seq2[*models.Cookie, error] {
+	return func(yield func(*models.Cookie, error) bool) {
+		for _, c := range src {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			if q.match(c) && !yield(c, nil) {
+				return
+			}
+		}
+	}
+}
+
+// mergeSource is a cookie source merging the cookies in a session store into
+// the cookies of a source. The source's cookies take precedence.
+type mergeSource struct {
+	src     source
+	session sessionSource
+}
+
+// cookies satisfies the source interface.
+func (src mergeSource) cookies(ctx context.Context, q *query) iter.Seq2[*models.Cookie, error] {
+	type key struct {
+		name, host, path, originAttributes string
+	}
+	return func(yield func(*models.Cookie, error) bool) {
+		seen := make(map[key]bool)
+		for c, err := range src.src.cookies(ctx, q) {
+			if err == nil {
+				seen[key{c.Name, c.Host, c.Path, c.OriginAttributes}] = true
+			}
+			if !yield(c, err) || err != nil {
+				return
+			}
+		}
+		for c, err := range src.session.cookies(ctx, q) {
+			if err == nil && seen[key{c.Name, c.Host, c.Path, c.OriginAttributes}] {
+				continue
+			}
+			if !yield(c, err) || err != nil {
+				return
+			}
+		}
+	}
+}
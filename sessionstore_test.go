@@ -0,0 +1,139 @@
+package ffcookies
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kenshaw/ffcookies/internal/mozlz4"
+)
+
+// writeSessionStore writes the session store file in the profile directory,
+// as mozlz4 compressed JSON (stored as a single LZ4 block of literals).
+func writeSessionStore(t *testing.T, dir, file, data string) {
+	t.Helper()
+	buf := binary.LittleEndian.AppendUint32([]byte(mozlz4.Magic), uint32(len(data)))
+	buf = append(buf, 0xf0)
+	n := len(data) - 0xf
+	for ; n >= 0xff; n -= 0xff {
+		buf = append(buf, 0xff)
+	}
+	buf = append(append(buf, byte(n)), data...)
+	writeFile(t, filepath.Join(dir, file), string(buf))
+}
+
+// testSessionStore is a session store with session cookies stored in the
+// session and in a window (as by older Firefox versions).
+const testSessionStore = `{
+  "version": ["sessionrestore", 1],
+  "windows": [
+    {
+      "tabs": [],
+      "cookies": [
+        {"host": ".session.test", "value": "2", "path": "/", "name": "window", "secure": true, "httponly": true, "originAttributes": {"userContextId": 0, "privateBrowsingId": 0, "firstPartyDomain": "", "geckoViewSessionContextId": "", "partitionKey": ""}}
+      ]
+    }
+  ],
+  "cookies": [
+    {"host": "session.test", "value": "1", "path": "/", "name": "session", "sameSite": 1, "schemeMap": 2, "originAttributes": {"userContextId": 0, "privateBrowsingId": 0, "firstPartyDomain": "", "geckoViewSessionContextId": "", "partitionKey": ""}},
+    {"host": ".hostonly.test", "value": "session", "path": "/", "name": "domain", "originAttributes": {}},
+    {"host": ".hostonly.test", "value": "3", "path": "/", "name": "session", "originAttributes": {}},
+    {"host": ".other.test", "value": "4", "path": "/", "name": "other", "originAttributes": {}}
+  ]
+}`
+
+func TestReadSessionStore(t *testing.T) {
+	dir := t.TempDir()
+	mkProfile(t, dir, testNow)
+	if _, err := ReadSessionStore(context.Background(), dir, "session.test"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected %v, got: %v", fs.ErrNotExist, err)
+	}
+	writeSessionStore(t, dir, filepath.Join("sessionstore-backups", "recovery.jsonlz4"), testSessionStore)
+	cookies, err := ReadSessionStore(context.Background(), dir, "session.test")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if names, exp := cookieNames(cookies), []string{"session", "window"}; !slices.Equal(names, exp) {
+		t.Fatalf("expected %v, got: %v", exp, names)
+	}
+	for _, cookie := range cookies {
+		switch {
+		case !cookie.Expires.IsZero():
+			t.Errorf("expected %s to be a session cookie, got: %v", cookie.Name, cookie.Expires)
+		case cookie.Name == "window" && (!cookie.Secure || !cookie.HttpOnly || cookie.Domain != ".session.test"):
+			t.Errorf("expected secure http only domain cookie, got: %v", cookie)
+		case cookie.Name == "session" && (cookie.Secure || cookie.Value != "1"):
+			t.Errorf("expected insecure cookie with value 1, got: %v", cookie)
+		}
+	}
+}
+
+func TestReadSessionStoreCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	mkProfile(t, dir, testNow)
+	writeFile(t, filepath.Join(dir, "sessionstore.jsonlz4"), "not mozlz4")
+	if _, err := ReadSessionStore(context.Background(), dir, ""); !errors.Is(err, mozlz4.ErrInvalidMagic) {
+		t.Errorf("expected %v, got: %v", mozlz4.ErrInvalidMagic, err)
+	}
+}
+
+func TestWithSessionStore(t *testing.T) {
+	dir := t.TempDir()
+	mkProfile(t, dir, testNow)
+	writeSessionStore(t, dir, "sessionstore.jsonlz4", testSessionStore)
+	file := filepath.Join(dir, "cookies.sqlite")
+	cookies, err := ReadFile(file, "hostonly.test", WithSessionStore())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// the database's cookies take precedence
+	var values []string
+	for _, cookie := range cookies {
+		values = append(values, cookie.Name+"="+cookie.Value)
+	}
+	slices.Sort(values)
+	if exp := []string{"domain=2", "host=1", "session=3"}; !slices.Equal(values, exp) {
+		t.Errorf("expected %v, got: %v", exp, values)
+	}
+	// no cookies are merged without a session store
+	cookies, err = ReadFile(testDB, "hostonly.test", WithSessionStore())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if names, exp := cookieNames(cookies), []string{"domain", "host"}; !slices.Equal(names, exp) {
+		t.Errorf("expected %v, got: %v", exp, names)
+	}
+}
+
+func TestOriginSuffix(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]any
+		exp   string
+	}{
+		{"empty", nil, ""},
+		{"defaults", map[string]any{"userContextId": float64(0), "privateBrowsingId": float64(0), "firstPartyDomain": "", "partitionKey": ""}, ""},
+		{"container", map[string]any{"userContextId": float64(2)}, "^userContextId=2"},
+		{"private", map[string]any{"privateBrowsingId": float64(1), "userContextId": float64(0)}, "^privateBrowsingId=1"},
+		{"partitioned", map[string]any{"partitionKey": "(https,example.com)"}, "^partitionKey=%28https%2Cexample.com%29"},
+		{"order", map[string]any{
+			"partitionKey":              "(https,example.com)",
+			"geckoViewSessionContextId": "ctx",
+			"firstPartyDomain":          "example.com",
+			"privateBrowsingId":         float64(1),
+			"userContextId":             float64(3),
+		}, "^userContextId=3&privateBrowsingId=1&firstPartyDomain=example.com&geckoViewSessionContextId=ctx&partitionKey=%28https%2Cexample.com%29"},
+		{"unknown", map[string]any{"inIsolatedMozBrowser": true, "userContextId": "2"}, "^userContextId=2"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if s := originSuffix(test.attrs); s != test.exp {
+				t.Errorf("expected %q, got: %q", test.exp, s)
+			}
+		})
+	}
+}
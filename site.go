@@ -0,0 +1,184 @@
+package ffcookies
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kenshaw/ffcookies/models"
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// CookiesForURL reads the cookies for the provided Firefox profile name (or
+// the default Firefox profile) that Firefox would send in a top-level request
+// to the url: cookies for the url's host and its parent domains that match the
+// url's path, excluding expired cookies and, for non-secure urls, Secure
+// cookies.
+//
+// Partitioned cookies are excluded, unless [WithIncludePartitioned] is passed,
+// in which case partitioned cookies keyed to the url's site are included.
+func CookiesForURL(ctx context.Context, profile, urlstr string, opts ...Option) ([]*http.Cookie, error) {
+	u, err := jarURL(urlstr)
+	if err != nil {
+		return nil, err
+	}
+	opts = append([]Option{urlOption(u)}, opts...)
+	cookies, err := ReadContext(ctx, profile, "", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return filterURL(u, cookies), nil
+}
+
+// urlOption returns a read option that selects the cookies for the url's host
+// and its parent domains, and the partitioned cookies for the url's site.
+func urlOption(u *url.URL) Option {
+	host := urlHost(u)
+	return func(o *options) {
+		o.hosts = hostCandidates(host, publicsuffix.List)
+		o.partitionSite = &models.PartitionKey{
+			Scheme: siteScheme(strings.ToLower(u.Scheme)),
+			Host:   siteHost(host),
+		}
+	}
+}
+
+// filterURL filters the cookies that do not match the url's path, and Secure
+// cookies when the url is not secure.
+func filterURL(u *url.URL, cookies []*http.Cookie) []*http.Cookie {
+	scheme := strings.ToLower(u.Scheme)
+	secure := scheme == "https" || scheme == "wss"
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	var res []*http.Cookie
+	for _, cookie := range cookies {
+		if (!cookie.Secure || secure) && pathMatch(path, cookie.Path) {
+			res = append(res, cookie)
+		}
+	}
+	return res
+}
+
+// filterPartitioned filters the partitioned cookies not keyed to the site.
+// Cookies with origin attributes that cannot be parsed are excluded.
+func filterPartitioned(res []*models.Cookie, site *models.PartitionKey, include bool) []*models.Cookie {
+	var cookies []*models.Cookie
+	for _, c := range res {
+		if partitionMatch(c, site, include) {
+			cookies = append(cookies, c)
+		}
+	}
+	return cookies
+}
+
+// partitionMatch returns true when the cookie is not partitioned, or when
+// include is true and the cookie is partitioned and keyed to the site.
+func partitionMatch(c *models.Cookie, site *models.PartitionKey, include bool) bool {
+	attrs, err := c.Origin()
+	switch {
+	case err != nil:
+		return false
+	case attrs.PartitionKey == nil:
+		return true
+	}
+	return include && attrs.PartitionKey.Scheme == site.Scheme && attrs.PartitionKey.Host == site.Host
+}
+
+// filterPartitionKey filters the cookies not partitioned with the key.
+func filterPartitionKey(res []*models.Cookie, key *models.PartitionKey) []*models.Cookie {
+	var cookies []*models.Cookie
+	for _, c := range res {
+		if partitionKeyMatch(c, key) {
+			cookies = append(cookies, c)
+		}
+	}
+	return cookies
+}
+
+// partitionKeyMatch returns true when the cookie is partitioned and keyed to
+// the same site as key.
+func partitionKeyMatch(c *models.Cookie, key *models.PartitionKey) bool {
+	attrs, err := c.Origin()
+	if err != nil || attrs.PartitionKey == nil {
+		return false
+	}
+	k := attrs.PartitionKey
+	return k.Scheme == key.Scheme && k.Host == key.Host && k.Port == key.Port
+}
+
+// hostCandidates returns the cookie hosts that domain-match host: the host
+// itself, and the host and its parent domains (up to the registrable domain,
+// as determined by the public suffix list) with a leading dot. A nil list
+// includes all parent domains.
+func hostCandidates(host string, list cookiejar.PublicSuffixList) []string {
+	hosts := []string{host}
+	if net.ParseIP(host) != nil {
+		return hosts
+	}
+	hosts = append(hosts, "."+host)
+	for h := host; ; {
+		i := strings.IndexByte(h, '.')
+		if i == -1 {
+			break
+		}
+		h = h[i+1:]
+		if list != nil && list.PublicSuffix(h) == h {
+			break
+		}
+		hosts = append(hosts, "."+h)
+	}
+	return hosts
+}
+
+// asciiHost converts an internationalized host name to its ASCII (punycode)
+// form, as stored by Firefox. Returns the host unchanged when it is already
+// ASCII or cannot be converted.
+func asciiHost(host string) string {
+	for i := 0; i < len(host); i++ {
+		if host[i] >= utf8.RuneSelf {
+			if s, err := idna.Lookup.ToASCII(host); err == nil {
+				return s
+			}
+			return host
+		}
+	}
+	return host
+}
+
+// siteHost returns the site host (the registrable domain) for host.
+func siteHost(host string) string {
+	if etld1, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return etld1
+	}
+	return host
+}
+
+// siteScheme returns the site scheme for scheme.
+func siteScheme(scheme string) string {
+	switch scheme {
+	case "wss":
+		return "https"
+	case "ws":
+		return "http"
+	}
+	return scheme
+}
+
+// pathMatch returns true when the request path path-matches the cookie path,
+// as defined by RFC 6265 section 5.1.4.
+func pathMatch(path, cookiePath string) bool {
+	switch {
+	case cookiePath == "" || path == cookiePath:
+		return true
+	case !strings.HasPrefix(path, cookiePath):
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || path[len(cookiePath)] == '/'
+}
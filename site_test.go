@@ -0,0 +1,30 @@
+package ffcookies
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestCookiesForURLPartitioned(t *testing.T) {
+	tests := []struct {
+		name   string
+		urlstr string
+		opts   []Option
+		exp    []string
+	}{
+		{"default", "https://app.widget.test/", nil, []string{"plain"}},
+		{"include", "https://app.widget.test/", []Option{WithIncludePartitioned(true)}, []string{"chips", "plain"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cookies, err := CookiesForURL(context.Background(), "", test.urlstr, append([]Option{WithCookieFile(testDB)}, test.opts...)...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if names := cookieNames(cookies); !slices.Equal(names, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package ffcookies
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+)
+
+// Snapshot writes a consistent copy of the cookie database in the provided
+// sqlite3 file to dest, which must not exist. The copy can be read while
+// Firefox continues to use the database. Pass [WithLiveDatabase] to include
+// the recently set cookies in the database's write-ahead log.
+//
+// With a sqlite3 driver, the copy is written using VACUUM INTO. Without a
+// sqlite3 driver, the database pages are copied as read.
+func Snapshot(ctx context.Context, file, dest string, opts ...Option) error {
+	return newOptions(opts...).writeSnapshot(ctx, file, dest)
+}
+
+// writeSnapshot writes a snapshot of the sqlite3 file to dest.
+func (o *options) writeSnapshot(ctx context.Context, file, dest string) error {
+	if err := dbExists(file); err != nil {
+		return err
+	}
+	if o.driver == "" && driverName() == "" {
+		db, err := o.openNative(file)
+		if err != nil {
+			return err
+		}
+		f, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err != nil {
+			return err
+		}
+		if _, err := db.WriteTo(f); err != nil {
+			_ = f.Close()
+			_ = os.Remove(dest)
+			return err
+		}
+		return f.Close()
+	}
+	db, closeDB, err := o.openLiveDB(ctx, file)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	o.logger.Debug("writing snapshot", "file", file, "dest", dest)
+	if _, err := db.ExecContext(ctx, `VACUUM INTO $1`, dest); err != nil {
+		return dbError(file, err)
+	}
+	return nil
+}
+
+// openSnapshot writes a snapshot of the sqlite3 file to a temporary
+// directory, and opens the snapshot. Returns the database and a func closing
+// it and removing the snapshot.
+func (o *options) openSnapshot(ctx context.Context, file string) (*sql.DB, func() error, error) {
+	dir, cleanup, err := o.mkTemp()
+	if err != nil {
+		return nil, nil, err
+	}
+	dest := filepath.Join(dir, "snapshot-"+filepath.Base(dbPath(file)))
+	// remove any previous snapshot (when the temporary directory is reused)
+	_ = os.Remove(dest)
+	if err := o.writeSnapshot(ctx, file, dest); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	db, err := o.openDB(ctx, fileURI(dest, "?mode=ro"))
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return db, func() error {
+		defer cleanup()
+		return db.Close()
+	}, nil
+}
@@ -0,0 +1,129 @@
+package ffcookies
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/kenshaw/ffcookies/models"
+)
+
+// CookieSource is a source of cookies, such as a browser's cookie store.
+// Reads use the Firefox cookie source ([FirefoxSource]), unless a cookie
+// source has been set with [WithSource].
+type CookieSource interface {
+	// Cookies returns the entries matching the filter.
+	Cookies(ctx context.Context, filter *Filter) ([]*Entry, error)
+}
+
+// Filter is the filter for the cookies read from a [CookieSource], built from
+// the read options.
+type Filter struct {
+	// Profile is the profile name, or empty for the default profile.
+	Profile string
+	// Host is the host, or empty for all hosts.
+	Host string
+
+	o *options
+}
+
+// NewFilter creates a filter for the host using the read options, such as
+// [WithProfile], [WithIncludeExpired] or [WithContainer].
+func NewFilter(host string, opts ...Option) *Filter {
+	o := newOptions(append([]Option{WithHost(host)}, opts...)...)
+	return o.filter()
+}
+
+// filter returns a filter for the options.
+func (o *options) filter() *Filter {
+	return &Filter{
+		Profile: o.profile,
+		Host:    o.host,
+		o:       o,
+	}
+}
+
+// options returns the filter's read options.
+func (f *Filter) options() *options {
+	if f.o == nil {
+		return newOptions(WithProfile(f.Profile), WithHost(f.Host))
+	}
+	return f.o
+}
+
+// Match returns true when the entry matches the filter's host and read
+// options, as the cookies read from a Firefox profile are matched. Cookie
+// sources can use Match to filter the cookies they return.
+func (f *Filter) Match(e *Entry) bool {
+	return f.options().query(f.Host).match(e.model())
+}
+
+// FirefoxSource is the cookie source for a Firefox profile, reading the
+// profile's cookie database with the filter's read options.
+type FirefoxSource struct {
+	// Profile is the profile name, overriding the filter's profile when not
+	// empty.
+	Profile string
+	// Options are additional read options.
+	Options []Option
+}
+
+// Cookies satisfies the [CookieSource] interface.
+func (src FirefoxSource) Cookies(ctx context.Context, filter *Filter) ([]*Entry, error) {
+	o := *filter.options()
+	o.source = nil
+	o.profile, o.host = filter.Profile, filter.Host
+	if src.Profile != "" {
+		o.profile = src.Profile
+	}
+	for _, opt := range src.Options {
+		opt(&o)
+	}
+	return o.readEntries(ctx)
+}
+
+// sourceCookies reads the cookies from the cookie source set with
+// [WithSource].
+func (o *options) sourceCookies(ctx context.Context) ([]*http.Cookie, error) {
+	entries, err := o.source.Cookies(ctx, o.filter())
+	if err != nil {
+		return nil, err
+	}
+	cookies := make([]*http.Cookie, 0, len(entries))
+	for _, e := range entries {
+		cookies = append(cookies, e.Cookie())
+	}
+	if err := o.sameSiteNone.apply(cookies); err != nil {
+		return nil, err
+	}
+	return cookies, nil
+}
+
+// model returns the entry as a Firefox cookie row.
+func (e *Entry) model() *models.Cookie {
+	c := &models.Cookie{
+		ID:               e.ID,
+		Host:             e.Host,
+		Name:             e.Name,
+		Value:            e.Value,
+		Path:             e.Path,
+		IsSecure:         e.Secure,
+		IsHTTPOnly:       e.HTTPOnly,
+		SameSite:         models.SameSiteFromHTTP(e.SameSite),
+		RawSameSite:      e.RawSameSite,
+		OriginAttributes: e.OriginAttributes,
+		SchemeMap:        e.SchemeMap,
+		InBrowserElement: e.InBrowserElement,
+		IsPartitioned:    e.Partitioned,
+		Extra:            e.Extra,
+	}
+	if !e.Expires.IsZero() {
+		c.Expiry = e.Expires.Unix()
+	}
+	if !e.CreationTime.IsZero() {
+		c.CreationTime = e.CreationTime.UnixMicro()
+	}
+	if !e.LastAccessed.IsZero() {
+		c.LastAccessed = e.LastAccessed.UnixMicro()
+	}
+	return c
+}
@@ -0,0 +1,179 @@
+package sshremote
+
+import (
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// remoteFS is the remote file system, rooted at the remote root directory.
+type remoteFS struct {
+	c *sftpClient
+}
+
+// remotePath returns the remote path for the named file.
+func remotePath(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return "/", nil
+	}
+	return "/" + name, nil
+}
+
+// Open satisfies the [fs.FS] interface.
+func (fsys remoteFS) Open(name string) (fs.File, error) {
+	p, err := remotePath("open", name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := fsys.c.stat(p, true)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if fi.IsDir() {
+		return &remoteDir{c: fsys.c, name: name, path: p, fi: fi}, nil
+	}
+	handle, err := fsys.c.open(p)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &remoteFile{c: fsys.c, name: name, handle: handle, fi: fi}, nil
+}
+
+// Stat satisfies the [fs.StatFS] interface.
+func (fsys remoteFS) Stat(name string) (fs.FileInfo, error) {
+	p, err := remotePath("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := fsys.c.stat(p, true)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return fi, nil
+}
+
+// lstat returns the file info of the named file, without following symlinks.
+func (fsys remoteFS) lstat(name string) (fs.FileInfo, error) {
+	p, err := remotePath("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := fsys.c.stat(p, false)
+	if err != nil {
+		return nil, &fs.PathError{Op: "lstat", Path: name, Err: err}
+	}
+	return fi, nil
+}
+
+// remoteFile is an open remote file.
+type remoteFile struct {
+	c      *sftpClient
+	name   string
+	handle string
+	fi     fs.FileInfo
+	off    int64
+}
+
+// Stat satisfies the [fs.File] interface.
+func (f *remoteFile) Stat() (fs.FileInfo, error) {
+	return f.fi, nil
+}
+
+// Read satisfies the [fs.File] interface.
+func (f *remoteFile) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	buf, err := f.c.read(f.handle, f.off, len(p))
+	switch {
+	case err == io.EOF:
+		return 0, io.EOF
+	case err != nil:
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: err}
+	}
+	n := copy(p, buf)
+	f.off += int64(n)
+	return n, nil
+}
+
+// Close satisfies the [fs.File] interface.
+func (f *remoteFile) Close() error {
+	return f.c.close(f.handle)
+}
+
+// remoteDir is an open remote directory.
+type remoteDir struct {
+	c       *sftpClient
+	name    string
+	path    string
+	fi      fs.FileInfo
+	entries []fs.DirEntry
+	read    bool
+}
+
+// Stat satisfies the [fs.File] interface.
+func (d *remoteDir) Stat() (fs.FileInfo, error) {
+	return d.fi, nil
+}
+
+// Read satisfies the [fs.File] interface.
+func (d *remoteDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+// Close satisfies the [fs.File] interface.
+func (d *remoteDir) Close() error {
+	return nil
+}
+
+// ReadDir satisfies the [fs.ReadDirFile] interface.
+func (d *remoteDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.readAll()
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: err}
+		}
+		d.entries, d.read = entries, true
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+// readAll reads all of the directory's entries, sorted by name.
+func (d *remoteDir) readAll() ([]fs.DirEntry, error) {
+	handle, err := d.c.opendir(d.path)
+	if err != nil {
+		return nil, err
+	}
+	defer d.c.close(handle)
+	var entries []fs.DirEntry
+	for {
+		infos, err := d.c.readdir(handle)
+		switch {
+		case err == io.EOF:
+			slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+				return strings.Compare(a.Name(), b.Name())
+			})
+			return entries, nil
+		case err != nil:
+			return nil, err
+		}
+		for _, fi := range infos {
+			entries = append(entries, fs.FileInfoToDirEntry(fi))
+		}
+	}
+}
@@ -0,0 +1,422 @@
+package sshremote
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// sftp packet types (see draft-ietf-secsh-filexfer-02).
+const (
+	fxpInit     = 1
+	fxpVersion  = 2
+	fxpOpen     = 3
+	fxpClose    = 4
+	fxpRead     = 5
+	fxpLstat    = 7
+	fxpOpendir  = 11
+	fxpReaddir  = 12
+	fxpRealpath = 16
+	fxpStat     = 17
+	fxpStatus   = 101
+	fxpHandle   = 102
+	fxpData     = 103
+	fxpName     = 104
+	fxpAttrs    = 105
+)
+
+// sftp status codes.
+const (
+	fxOK               = 0
+	fxEOF              = 1
+	fxNoSuchFile       = 2
+	fxPermissionDenied = 3
+)
+
+// sftp attribute flags.
+const (
+	attrSize        = 0x1
+	attrUIDGID      = 0x2
+	attrPermissions = 0x4
+	attrACModTime   = 0x8
+	attrExtended    = 0x80000000
+)
+
+// maxRead is the maximum length of a read request.
+const maxRead = 32768
+
+// sftpClient is a minimal, read-only sftp (version 3) client.
+type sftpClient struct {
+	session io.Closer
+	w       io.WriteCloser
+	r       *bufio.Reader
+	// mu protects the request id and the connection
+	mu sync.Mutex
+	id uint32
+}
+
+// newSFTP starts the sftp subsystem on the ssh connection.
+func newSFTP(conn *ssh.Client) (*sftpClient, error) {
+	session, err := conn.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	w, err := session.StdinPipe()
+	if err != nil {
+		_ = session.Close()
+		return nil, err
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		_ = session.Close()
+		return nil, err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		_ = session.Close()
+		return nil, fmt.Errorf("sftp: %w", err)
+	}
+	return startSFTP(session, r, w)
+}
+
+// startSFTP negotiates the sftp version with the server over r and w. The
+// session is closed when the client is closed.
+func startSFTP(session io.Closer, r io.Reader, w io.WriteCloser) (*sftpClient, error) {
+	c := &sftpClient{
+		session: session,
+		w:       w,
+		r:       bufio.NewReader(r),
+	}
+	// negotiate version
+	if err := c.writePacket(fxpInit, binary.BigEndian.AppendUint32(nil, 3)); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	switch typ, _, err := c.readPacket(); {
+	case err != nil:
+		_ = c.Close()
+		return nil, err
+	case typ != fxpVersion:
+		_ = c.Close()
+		return nil, fmt.Errorf("sftp: unexpected packet type %d", typ)
+	}
+	return c, nil
+}
+
+// Close closes the sftp session.
+func (c *sftpClient) Close() error {
+	_ = c.w.Close()
+	return c.session.Close()
+}
+
+// writePacket writes a packet.
+func (c *sftpClient) writePacket(typ byte, data []byte) error {
+	buf := binary.BigEndian.AppendUint32(nil, uint32(len(data)+1))
+	buf = append(buf, typ)
+	_, err := c.w.Write(append(buf, data...))
+	return err
+}
+
+// readPacket reads a packet.
+func (c *sftpClient) readPacket() (byte, []byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(c.r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[:4])
+	if n < 1 || n > 1<<24 {
+		return 0, nil, fmt.Errorf("sftp: invalid packet length %d", n)
+	}
+	data := make([]byte, n-1)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return 0, nil, err
+	}
+	return hdr[4], data, nil
+}
+
+// request sends a request, returning the response type and data. Status
+// responses other than OK are returned as errors.
+func (c *sftpClient) request(typ byte, data []byte) (byte, *decoder, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.id++
+	if err := c.writePacket(typ, append(binary.BigEndian.AppendUint32(nil, c.id), data...)); err != nil {
+		return 0, nil, err
+	}
+	typ, buf, err := c.readPacket()
+	if err != nil {
+		return 0, nil, err
+	}
+	d := &decoder{buf: buf}
+	if id := d.uint32(); d.err == nil && id != c.id {
+		return 0, nil, fmt.Errorf("sftp: unexpected response id %d", id)
+	}
+	if typ == fxpStatus {
+		if err := d.status(); err != nil {
+			return 0, nil, err
+		}
+	}
+	return typ, d, d.err
+}
+
+// expect sends a request, checking the response type.
+func (c *sftpClient) expect(typ byte, data []byte, want byte) (*decoder, error) {
+	typ, d, err := c.request(typ, data)
+	switch {
+	case err != nil:
+		return nil, err
+	case typ != want:
+		return nil, fmt.Errorf("sftp: unexpected packet type %d", typ)
+	}
+	return d, nil
+}
+
+// realpath returns the canonical absolute path of name.
+func (c *sftpClient) realpath(name string) (string, error) {
+	d, err := c.expect(fxpRealpath, appendString(nil, name), fxpName)
+	if err != nil {
+		return "", err
+	}
+	if d.uint32() < 1 {
+		return "", errors.New("sftp: empty realpath response")
+	}
+	return d.string(), d.err
+}
+
+// stat returns the file info of name, following symlinks when follow is
+// true.
+func (c *sftpClient) stat(name string, follow bool) (*fileInfo, error) {
+	typ := byte(fxpLstat)
+	if follow {
+		typ = fxpStat
+	}
+	d, err := c.expect(typ, appendString(nil, name), fxpAttrs)
+	if err != nil {
+		return nil, err
+	}
+	fi := d.attrs(path.Base(name))
+	return fi, d.err
+}
+
+// open opens the file for reading, returning its handle.
+func (c *sftpClient) open(name string) (string, error) {
+	data := appendString(nil, name)
+	data = binary.BigEndian.AppendUint32(data, 1) // SSH_FXF_READ
+	data = binary.BigEndian.AppendUint32(data, 0) // no attributes
+	d, err := c.expect(fxpOpen, data, fxpHandle)
+	if err != nil {
+		return "", err
+	}
+	return d.string(), d.err
+}
+
+// opendir opens the directory, returning its handle.
+func (c *sftpClient) opendir(name string) (string, error) {
+	d, err := c.expect(fxpOpendir, appendString(nil, name), fxpHandle)
+	if err != nil {
+		return "", err
+	}
+	return d.string(), d.err
+}
+
+// read reads up to n bytes at offset off from the file handle. Returns
+// [io.EOF] at the end of the file.
+func (c *sftpClient) read(handle string, off int64, n int) ([]byte, error) {
+	data := appendString(nil, handle)
+	data = binary.BigEndian.AppendUint64(data, uint64(off))
+	data = binary.BigEndian.AppendUint32(data, uint32(min(n, maxRead)))
+	d, err := c.expect(fxpRead, data, fxpData)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(d.string()), d.err
+}
+
+// readdir reads the next entries of the directory handle, skipping the . and
+// .. entries. Returns [io.EOF] at the end of the directory.
+func (c *sftpClient) readdir(handle string) ([]*fileInfo, error) {
+	d, err := c.expect(fxpReaddir, appendString(nil, handle), fxpName)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*fileInfo
+	for n := d.uint32(); n > 0 && d.err == nil; n-- {
+		name := d.string()
+		_ = d.string() // long name
+		if fi := d.attrs(name); name != "." && name != ".." {
+			entries = append(entries, fi)
+		}
+	}
+	return entries, d.err
+}
+
+// close closes the handle.
+func (c *sftpClient) close(handle string) error {
+	_, _, err := c.request(fxpClose, appendString(nil, handle))
+	return err
+}
+
+// appendString appends a length prefixed string.
+func appendString(buf []byte, s string) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(s)))
+	return append(buf, s...)
+}
+
+// decoder decodes the data of a packet.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+// uint32 decodes a uint32.
+func (d *decoder) uint32() uint32 {
+	if len(d.buf) < 4 {
+		d.fail()
+		return 0
+	}
+	v := binary.BigEndian.Uint32(d.buf)
+	d.buf = d.buf[4:]
+	return v
+}
+
+// uint64 decodes a uint64.
+func (d *decoder) uint64() uint64 {
+	if len(d.buf) < 8 {
+		d.fail()
+		return 0
+	}
+	v := binary.BigEndian.Uint64(d.buf)
+	d.buf = d.buf[8:]
+	return v
+}
+
+// string decodes a length prefixed string.
+func (d *decoder) string() string {
+	n := d.uint32()
+	if uint32(len(d.buf)) < n {
+		d.fail()
+		return ""
+	}
+	s := string(d.buf[:n])
+	d.buf = d.buf[n:]
+	return s
+}
+
+// fail sets the short packet error.
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = errors.New("sftp: short packet")
+	}
+	d.buf = nil
+}
+
+// status decodes a status response as an error.
+func (d *decoder) status() error {
+	code := d.uint32()
+	msg := d.string()
+	switch {
+	case d.err != nil:
+		return d.err
+	case code == fxOK:
+		return nil
+	case code == fxEOF:
+		return io.EOF
+	case code == fxNoSuchFile:
+		return fs.ErrNotExist
+	case code == fxPermissionDenied:
+		return fs.ErrPermission
+	}
+	return fmt.Errorf("sftp: %s (status %d)", msg, code)
+}
+
+// attrs decodes file attributes.
+func (d *decoder) attrs(name string) *fileInfo {
+	fi := &fileInfo{
+		name: name,
+	}
+	flags := d.uint32()
+	if flags&attrSize != 0 {
+		fi.size = int64(d.uint64())
+	}
+	if flags&attrUIDGID != 0 {
+		_, _ = d.uint32(), d.uint32()
+	}
+	if flags&attrPermissions != 0 {
+		fi.mode = fileMode(d.uint32())
+	}
+	if flags&attrACModTime != 0 {
+		_ = d.uint32() // access time
+		fi.modTime = time.Unix(int64(d.uint32()), 0)
+	}
+	if flags&attrExtended != 0 {
+		for n := d.uint32(); n > 0 && d.err == nil; n-- {
+			_, _ = d.string(), d.string()
+		}
+	}
+	return fi
+}
+
+// fileMode converts posix permissions to a file mode.
+func fileMode(perm uint32) fs.FileMode {
+	mode := fs.FileMode(perm & 0o777)
+	switch perm & 0o170000 {
+	case 0o040000:
+		mode |= fs.ModeDir
+	case 0o120000:
+		mode |= fs.ModeSymlink
+	case 0o010000:
+		mode |= fs.ModeNamedPipe
+	case 0o140000:
+		mode |= fs.ModeSocket
+	case 0o020000:
+		mode |= fs.ModeDevice | fs.ModeCharDevice
+	case 0o060000:
+		mode |= fs.ModeDevice
+	}
+	return mode
+}
+
+// fileInfo is the info for a remote file.
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// Name satisfies the [fs.FileInfo] interface.
+func (fi *fileInfo) Name() string {
+	return fi.name
+}
+
+// Size satisfies the [fs.FileInfo] interface.
+func (fi *fileInfo) Size() int64 {
+	return fi.size
+}
+
+// Mode satisfies the [fs.FileInfo] interface.
+func (fi *fileInfo) Mode() fs.FileMode {
+	return fi.mode
+}
+
+// ModTime satisfies the [fs.FileInfo] interface.
+func (fi *fileInfo) ModTime() time.Time {
+	return fi.modTime
+}
+
+// IsDir satisfies the [fs.FileInfo] interface.
+func (fi *fileInfo) IsDir() bool {
+	return fi.mode.IsDir()
+}
+
+// Sys satisfies the [fs.FileInfo] interface.
+func (fi *fileInfo) Sys() any {
+	return nil
+}
@@ -0,0 +1,455 @@
+package sshremote
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/kenshaw/ffcookies"
+)
+
+// testServer is a minimal, in-process sftp (version 3) server for the files
+// in fsys, with the remote home directory /home.
+type testServer struct {
+	fsys fstest.MapFS
+	// maxRead limits the length of read responses when not 0.
+	maxRead int
+	// onRead is called with the file name and its count of read requests,
+	// returning a status code to respond with instead of the data when not 0.
+	onRead func(string, int) uint32
+	// reads are the counts of read requests, by file name.
+	reads map[string]int
+	// handles are the paths of the open handles.
+	handles map[string]string
+}
+
+// newTestClient starts the server over an in-memory connection, returning a
+// client for the remote profile directory.
+func newTestClient(t *testing.T, s *testServer) *Client {
+	t.Helper()
+	client, server := net.Pipe()
+	go s.serve(server)
+	c, err := startSFTP(client, client, client)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return &Client{
+		sftp:         c,
+		fs:           remoteFS{c},
+		lockStrategy: ffcookies.LockAuto,
+	}
+}
+
+// serve serves requests on the connection until it is closed.
+func (s *testServer) serve(conn net.Conn) {
+	defer conn.Close()
+	s.handles, s.reads = make(map[string]string), make(map[string]int)
+	r := bufio.NewReader(conn)
+	for {
+		var hdr [5]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return
+		}
+		data := make([]byte, binary.BigEndian.Uint32(hdr[:4])-1)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return
+		}
+		if hdr[4] == fxpInit {
+			_ = writeTestPacket(conn, fxpVersion, binary.BigEndian.AppendUint32(nil, 3))
+			continue
+		}
+		d := &decoder{buf: data}
+		id := d.uint32()
+		typ, buf := s.handle(hdr[4], d)
+		if err := writeTestPacket(conn, typ, append(binary.BigEndian.AppendUint32(nil, id), buf...)); err != nil {
+			return
+		}
+	}
+}
+
+// handle handles a request, returning the response type and data.
+func (s *testServer) handle(typ byte, d *decoder) (byte, []byte) {
+	switch typ {
+	case fxpRealpath:
+		return fxpName, appendTestName(nil, path.Join("/home", d.string()), nil)
+	case fxpStat, fxpLstat:
+		name := fsPath(d.string())
+		if f := s.fsys[name]; typ == fxpLstat && f != nil && f.Mode.Type() == fs.ModeSymlink {
+			return fxpAttrs, appendTestAttrs(nil, &fileInfo{name: path.Base(name), mode: f.Mode})
+		}
+		fi, err := fs.Stat(s.fsys, name)
+		if err != nil {
+			return testStatus(err)
+		}
+		return fxpAttrs, appendTestAttrs(nil, fi)
+	case fxpOpen, fxpOpendir:
+		name := fsPath(d.string())
+		if _, err := fs.Stat(s.fsys, name); err != nil {
+			return testStatus(err)
+		}
+		handle := strconv.Itoa(len(s.handles))
+		s.handles[handle] = name
+		return fxpHandle, appendString(nil, handle)
+	case fxpRead:
+		name, off, n := s.handles[d.string()], d.uint64(), int(d.uint32())
+		s.reads[name]++
+		if s.onRead != nil {
+			if code := s.onRead(name, s.reads[name]); code != fxOK {
+				return fxpStatus, appendTestStatus(nil, code, "read failed")
+			}
+		}
+		buf := s.fsys[name].Data
+		if off >= uint64(len(buf)) {
+			return fxpStatus, appendTestStatus(nil, fxEOF, "eof")
+		}
+		if s.maxRead != 0 {
+			n = min(n, s.maxRead)
+		}
+		return fxpData, appendString(nil, string(buf[off:min(off+uint64(n), uint64(len(buf)))]))
+	case fxpReaddir:
+		handle := d.string()
+		name, ok := s.handles[handle]
+		if !ok {
+			return fxpStatus, appendTestStatus(nil, fxEOF, "eof")
+		}
+		delete(s.handles, handle)
+		entries, err := fs.ReadDir(s.fsys, name)
+		if err != nil {
+			return testStatus(err)
+		}
+		var infos []fs.FileInfo
+		for _, entry := range entries {
+			fi, err := entry.Info()
+			if err != nil {
+				return testStatus(err)
+			}
+			infos = append(infos, fi)
+		}
+		return fxpName, appendTestName(nil, "", infos)
+	case fxpClose:
+		delete(s.handles, d.string())
+		return fxpStatus, appendTestStatus(nil, fxOK, "")
+	}
+	return fxpStatus, appendTestStatus(nil, 8, "operation unsupported")
+}
+
+// writeTestPacket writes a packet.
+func writeTestPacket(w io.Writer, typ byte, data []byte) error {
+	buf := binary.BigEndian.AppendUint32(nil, uint32(len(data)+1))
+	_, err := w.Write(append(append(buf, typ), data...))
+	return err
+}
+
+// testStatus returns the status response for the error.
+func testStatus(err error) (byte, []byte) {
+	code := uint32(4) // SSH_FX_FAILURE
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		code = fxNoSuchFile
+	case errors.Is(err, fs.ErrPermission):
+		code = fxPermissionDenied
+	}
+	return fxpStatus, appendTestStatus(nil, code, err.Error())
+}
+
+// appendTestStatus appends a status response.
+func appendTestStatus(buf []byte, code uint32, msg string) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, code)
+	buf = appendString(buf, msg)
+	return appendString(buf, "en")
+}
+
+// appendTestName appends a name response, for the single name when infos is
+// nil.
+func appendTestName(buf []byte, name string, infos []fs.FileInfo) []byte {
+	if infos == nil {
+		buf = binary.BigEndian.AppendUint32(buf, 1)
+		buf = appendString(appendString(buf, name), name)
+		return binary.BigEndian.AppendUint32(buf, 0)
+	}
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(infos)+2))
+	for _, name := range []string{".", ".."} {
+		buf = appendString(appendString(buf, name), name)
+		buf = binary.BigEndian.AppendUint32(buf, 0)
+	}
+	for _, fi := range infos {
+		buf = appendString(appendString(buf, fi.Name()), fi.Name())
+		buf = appendTestAttrs(buf, fi)
+	}
+	return buf
+}
+
+// appendTestAttrs appends the file attributes.
+func appendTestAttrs(buf []byte, fi fs.FileInfo) []byte {
+	perm := uint32(fi.Mode().Perm())
+	switch fi.Mode().Type() {
+	case fs.ModeDir:
+		perm |= 0o040000
+	case fs.ModeSymlink:
+		perm |= 0o120000
+	default:
+		perm |= 0o100000
+	}
+	buf = binary.BigEndian.AppendUint32(buf, attrSize|attrPermissions|attrACModTime)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(fi.Size()))
+	buf = binary.BigEndian.AppendUint32(buf, perm)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(fi.ModTime().Unix()))
+	return binary.BigEndian.AppendUint32(buf, uint32(fi.ModTime().Unix()))
+}
+
+// testDB is the name of the cookie database in the test file system.
+const testDB = "home/.mozilla/firefox/abc.default-release/cookies.sqlite"
+
+// testProfile returns a file system with a remote profile, with the cookie
+// database data.
+func testProfile(data string) fstest.MapFS {
+	return fstest.MapFS{
+		"home/.mozilla/firefox/profiles.ini": &fstest.MapFile{
+			Data: []byte("[Profile0]\nName=default-release\nIsRelative=1\nPath=abc.default-release\nDefault=1\n"),
+		},
+		testDB: &fstest.MapFile{
+			Data:    []byte(data),
+			ModTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func TestOpenReadClose(t *testing.T) {
+	data := strings.Repeat("0123456789", 10000)
+	s := &testServer{fsys: testProfile(data)}
+	c := newTestClient(t, s)
+	f, err := c.FS().Open(testDB)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fi, err := f.Stat()
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case fi.Size() != int64(len(data)):
+		t.Errorf("expected size %d, got: %d", len(data), fi.Size())
+	case !fi.ModTime().Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)):
+		t.Errorf("expected mod time 2025-01-01, got: %v", fi.ModTime())
+	}
+	buf, err := io.ReadAll(f)
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case string(buf) != data:
+		t.Errorf("expected %d bytes, got: %d", len(data), len(buf))
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(s.handles) != 0 {
+		t.Errorf("expected no open handles, got: %v", s.handles)
+	}
+}
+
+func TestShortReads(t *testing.T) {
+	data := strings.Repeat("0123456789", 100)
+	s := &testServer{fsys: testProfile(data), maxRead: 7}
+	c := newTestClient(t, s)
+	name, live, err := c.Copy(context.Background(), "", t.TempDir())
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case live:
+		t.Errorf("expected no write-ahead log")
+	}
+	buf, err := os.ReadFile(name)
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case string(buf) != data:
+		t.Errorf("expected %d bytes, got: %d", len(data), len(buf))
+	}
+	if exp := (len(data)+6)/7 + 1; s.reads[testDB] != exp {
+		t.Errorf("expected %d read requests, got: %d", exp, s.reads[testDB])
+	}
+}
+
+func TestStatusErrors(t *testing.T) {
+	tests := []struct {
+		code uint32
+		exp  error
+	}{
+		{fxEOF, io.EOF},
+		{fxNoSuchFile, fs.ErrNotExist},
+		{fxPermissionDenied, fs.ErrPermission},
+		{4, nil},
+	}
+	for _, test := range tests {
+		t.Run(strconv.Itoa(int(test.code)), func(t *testing.T) {
+			s := &testServer{
+				fsys: testProfile("data"),
+				onRead: func(string, int) uint32 {
+					return test.code
+				},
+			}
+			c := newTestClient(t, s)
+			f, err := c.FS().Open(testDB)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			defer f.Close()
+			_, err = f.Read(make([]byte, 16))
+			switch {
+			case err == nil:
+				t.Fatalf("expected error")
+			case test.exp != nil && !errors.Is(err, test.exp):
+				t.Errorf("expected %v, got: %v", test.exp, err)
+			case test.exp == nil && !strings.Contains(err.Error(), "read failed (status 4)"):
+				t.Errorf("expected status error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestOpenNotExist(t *testing.T) {
+	c := newTestClient(t, &testServer{fsys: testProfile("data")})
+	if _, err := c.FS().Open("home/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected %v, got: %v", fs.ErrNotExist, err)
+	}
+	if _, err := c.FS().Open("/home"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("expected %v, got: %v", fs.ErrInvalid, err)
+	}
+}
+
+func TestReadDir(t *testing.T) {
+	c := newTestClient(t, &testServer{fsys: testProfile("data")})
+	entries, err := fs.ReadDir(c.FS(), "home/.mozilla/firefox")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if exp := []string{"abc.default-release", "profiles.ini"}; !slices.Equal(names, exp) {
+		t.Errorf("expected %v, got: %v", exp, names)
+	}
+	if !entries[0].IsDir() {
+		t.Errorf("expected %s to be a directory", entries[0].Name())
+	}
+}
+
+func TestShortPacket(t *testing.T) {
+	d := &decoder{buf: []byte{0, 0, 0, 8, 'a', 'b'}}
+	if s := d.string(); s != "" || d.err == nil {
+		t.Errorf("expected short packet error, got: %q, %v", s, d.err)
+	}
+	if v := d.uint32(); v != 0 {
+		t.Errorf("expected 0, got: %d", v)
+	}
+}
+
+func TestCopyLocked(t *testing.T) {
+	fsys := testProfile("data")
+	fsys["home/.mozilla/firefox/abc.default-release/lock"] = &fstest.MapFile{
+		Data: []byte("127.0.0.1:+1234"),
+		Mode: fs.ModeSymlink | 0o777,
+	}
+	fsys["home/.mozilla/firefox/abc.default-release/cookies.sqlite-wal"] = &fstest.MapFile{
+		Data: []byte("wal"),
+	}
+	tests := []struct {
+		strategy ffcookies.LockStrategy
+		live     bool
+		err      error
+	}{
+		{ffcookies.LockAuto, true, nil},
+		{ffcookies.LockCopy, true, nil},
+		{ffcookies.LockFail, false, ErrProfileInUse},
+	}
+	for _, test := range tests {
+		t.Run(strconv.Itoa(int(test.strategy)), func(t *testing.T) {
+			c := newTestClient(t, &testServer{fsys: fsys})
+			c.lockStrategy = test.strategy
+			inUse, err := c.InUse(context.Background(), "/home/.mozilla/firefox/abc.default-release")
+			switch {
+			case err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			case !inUse:
+				t.Errorf("expected profile in use")
+			}
+			dir := t.TempDir()
+			name, live, err := c.Copy(context.Background(), "default-release", dir)
+			if test.err != nil {
+				var lockErr *ffcookies.LockedError
+				switch {
+				case !errors.As(err, &lockErr):
+					t.Fatalf("expected locked error, got: %v", err)
+				case !errors.Is(err, test.err):
+					t.Errorf("expected %v, got: %v", test.err, err)
+				}
+				return
+			}
+			switch {
+			case err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			case live != test.live:
+				t.Errorf("expected live %t, got: %t", test.live, live)
+			case name != filepath.Join(dir, "cookies.sqlite"):
+				t.Errorf("expected %s, got: %s", filepath.Join(dir, "cookies.sqlite"), name)
+			}
+			if buf, err := os.ReadFile(name + "-wal"); err != nil || string(buf) != "wal" {
+				t.Errorf("expected write-ahead log copy, got: %q, %v", buf, err)
+			}
+		})
+	}
+}
+
+func TestCopyCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &testServer{
+		fsys:    testProfile(strings.Repeat("0123456789", 100)),
+		maxRead: 10,
+		onRead: func(name string, n int) uint32 {
+			if name == testDB && n == 2 {
+				cancel()
+			}
+			return fxOK
+		},
+	}
+	c := newTestClient(t, s)
+	if _, _, err := c.Copy(ctx, "", t.TempDir()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got: %v", context.Canceled, err)
+	}
+	if s.reads[testDB] != 2 {
+		t.Errorf("expected 2 read requests, got: %d", s.reads[testDB])
+	}
+}
+
+func TestReadContext(t *testing.T) {
+	buf, err := os.ReadFile("../testdata/cookies.sqlite")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	c := newTestClient(t, &testServer{fsys: testProfile(string(buf))})
+	if dir, err := c.ProfileDir(context.Background()); err != nil || dir != "/home/.mozilla/firefox" {
+		t.Errorf("expected /home/.mozilla/firefox, got: %q, %v", dir, err)
+	}
+	cookies, err := c.ReadContext(context.Background(), "", "emptyname.test")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cookies) != 1 || cookies[0].Name != "ok" {
+		t.Errorf("expected cookie ok, got: %v", cookies)
+	}
+}
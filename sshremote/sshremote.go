@@ -0,0 +1,294 @@
+// Package sshremote reads Firefox cookies from a remote host over SSH, copying
+// the remote profile's cookie database with SFTP and reading the copy with
+// [ffcookies.ReadFileContext].
+package sshremote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/kenshaw/ffcookies"
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrProfileInUse is the profile in use error, returned as the underlying
+// error of a [ffcookies.LockedError] when the remote profile is in use by a
+// running Firefox and the lock strategy is [ffcookies.LockFail].
+var ErrProfileInUse = errors.New("remote profile in use")
+
+// Client reads Firefox cookies from a remote host.
+type Client struct {
+	conn         *ssh.Client
+	sftp         *sftpClient
+	fs           remoteFS
+	owned        bool
+	profileDir   string
+	lockStrategy ffcookies.LockStrategy
+}
+
+// Dial connects to the ssh server at addr, returning a client that closes the
+// connection when closed.
+func Dial(addr string, config *ssh.ClientConfig, opts ...Option) (*Client, error) {
+	conn, err := ssh.Dial("tcp", addr, config)
+	if err != nil {
+		return nil, err
+	}
+	c, err := New(conn, opts...)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	c.owned = true
+	return c, nil
+}
+
+// New creates a client for an established ssh connection. The connection is
+// not closed when the client is closed.
+func New(conn *ssh.Client, opts ...Option) (*Client, error) {
+	sftp, err := newSFTP(conn)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		conn:         conn,
+		sftp:         sftp,
+		fs:           remoteFS{sftp},
+		lockStrategy: ffcookies.LockAuto,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c, nil
+}
+
+// Close closes the client.
+func (c *Client) Close() error {
+	err := c.sftp.Close()
+	if c.owned {
+		if closeErr := c.conn.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// FS returns the remote file system, rooted at the remote root directory.
+// Absolute remote paths are opened without the leading slash.
+func (c *Client) FS() fs.FS {
+	return c.fs
+}
+
+// ProfileDir returns the remote base profile directory for Firefox (the
+// directory containing profiles.ini), as set by [WithProfileDir] or the first
+// of the Linux, macOS, and Windows candidate directories in the remote user's
+// home directory that exists.
+func (c *Client) ProfileDir(ctx context.Context) (string, error) {
+	if c.profileDir != "" {
+		return c.profileDir, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	home, err := c.sftp.realpath(".")
+	if err != nil {
+		return "", fmt.Errorf("cannot determine the remote home directory: %w", err)
+	}
+	var dirs []string
+	for _, d := range []string{
+		".mozilla/firefox",
+		"snap/firefox/common/.mozilla/firefox",
+		".var/app/org.mozilla.firefox/.mozilla/firefox",
+		"Library/Application Support/Firefox",
+		"AppData/Roaming/Mozilla/Firefox",
+	} {
+		dir := path.Join(home, d)
+		if fi, err := c.fs.Stat(fsPath(dir)); err == nil && fi.IsDir() {
+			return dir, nil
+		}
+		dirs = append(dirs, dir)
+	}
+	return "", fmt.Errorf("%w (tried %s)", ffcookies.ErrProfileDirNotFound, strings.Join(dirs, ", "))
+}
+
+// CookiePath returns the remote path of the cookie database for the profile,
+// or the default profile when profile is empty.
+func (c *Client) CookiePath(ctx context.Context, profile string) (string, error) {
+	dir, err := c.ProfileDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	name, err := ffcookies.CookiePathFS(c.fs, fsPath(dir), profile)
+	if err != nil {
+		return "", err
+	}
+	return "/" + name, nil
+}
+
+// InUse returns true when the remote profile directory is in use by a running
+// Firefox, as determined by the profile's lock symlink.
+func (c *Client) InUse(ctx context.Context, dir string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	switch _, err := c.fs.lstat(fsPath(path.Join(dir, "lock"))); {
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	return true, nil
+}
+
+// Copy copies the cookie database of the remote profile (or the default
+// profile when profile is empty) to the local directory dir, returning the
+// path of the copy, and whether its write-ahead log was also copied.
+//
+// The lock strategy determines how a profile in use by a running Firefox is
+// handled. With [ffcookies.LockFail], a [ffcookies.LockedError] is returned.
+// Otherwise, the database's write-ahead log is copied alongside the database,
+// so that the copy includes the cookies not yet written to the database.
+// Remote databases are always read from a copy, so [ffcookies.LockRetry]
+// behaves as [ffcookies.LockCopy].
+func (c *Client) Copy(ctx context.Context, profile, dir string) (string, bool, error) {
+	name, err := c.CookiePath(ctx, profile)
+	if err != nil {
+		return "", false, err
+	}
+	inUse, err := c.InUse(ctx, path.Dir(name))
+	switch {
+	case err != nil:
+		return "", false, err
+	case inUse && c.lockStrategy == ffcookies.LockFail:
+		return "", false, &ffcookies.LockedError{
+			File:  name,
+			InUse: true,
+			Err:   ErrProfileInUse,
+		}
+	}
+	dest := filepath.Join(dir, path.Base(name))
+	switch err := c.copyFile(ctx, dest, name); {
+	case errors.Is(err, fs.ErrNotExist):
+		return "", false, fmt.Errorf("%w: %s", ffcookies.ErrCookieDBNotFound, name)
+	case err != nil:
+		return "", false, err
+	}
+	// remove any stale write-ahead log (when dir is reused)
+	_ = os.Remove(dest + "-wal")
+	_ = os.Remove(dest + "-shm")
+	if !inUse {
+		return dest, false, nil
+	}
+	switch err := c.copyFile(ctx, dest+"-wal", name+"-wal"); {
+	case errors.Is(err, fs.ErrNotExist):
+		return dest, false, nil
+	case err != nil:
+		return "", false, err
+	}
+	return dest, true, nil
+}
+
+// copyFile copies the remote file src to the local file dst, checking the
+// context between read requests.
+func (c *Client) copyFile(ctx context.Context, dst, src string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	in, err := c.fs.Open(fsPath(src))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := copyContext(ctx, out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// copyContext copies r to w, reading at most one sftp read request at a time
+// and checking the context before each read.
+func copyContext(ctx context.Context, w io.Writer, r io.Reader) error {
+	buf := make([]byte, maxRead)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := r.Read(buf)
+		if n != 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+		switch {
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			return err
+		}
+	}
+}
+
+// ReadContext reads the cookies for the host from the remote profile (or the
+// default profile when profile is empty), using the read options. The cookie
+// database is copied to a temporary directory (see [Client.Copy]) and read
+// with [ffcookies.ReadFileContext].
+func (c *Client) ReadContext(ctx context.Context, profile, host string, opts ...ffcookies.Option) ([]*http.Cookie, error) {
+	dir, err := os.MkdirTemp("", "ffcookies-ssh-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	name, live, err := c.Copy(ctx, profile, dir)
+	if err != nil {
+		return nil, err
+	}
+	if live {
+		opts = append(opts, ffcookies.WithLiveDatabase())
+	}
+	return ffcookies.ReadFileContext(ctx, name, host, opts...)
+}
+
+// Read reads the cookies for the host from the remote profile.
+func (c *Client) Read(profile, host string, opts ...ffcookies.Option) ([]*http.Cookie, error) {
+	return c.ReadContext(context.Background(), profile, host, opts...)
+}
+
+// fsPath returns the path in the remote file system for the remote path.
+func fsPath(name string) string {
+	if name = strings.TrimPrefix(path.Clean(name), "/"); name == "" {
+		return "."
+	}
+	return name
+}
+
+// Option is a client option.
+type Option func(*Client)
+
+// WithProfileDir is a client option to set the remote base profile directory
+// for Firefox (the directory containing profiles.ini).
+func WithProfileDir(dir string) Option {
+	return func(c *Client) {
+		c.profileDir = dir
+	}
+}
+
+// WithLockStrategy is a client option to set the strategy for reading the
+// cookie database of a remote profile in use by a running Firefox. Defaults to
+// [ffcookies.LockAuto]. See [Client.Copy].
+func WithLockStrategy(strategy ffcookies.LockStrategy) Option {
+	return func(c *Client) {
+		c.lockStrategy = strategy
+	}
+}
@@ -0,0 +1,32 @@
+package ffcookies
+
+import (
+	"context"
+
+	"github.com/kenshaw/ffcookies/models"
+)
+
+// Stats returns aggregate statistics for the cookies of the provided Firefox
+// profile name, or the default Firefox profile.
+//
+// The statistics are computed for the cookies matching the read options (such
+// as [WithHost] and [WithContainer]), excluding private browsing cookies by
+// default (see [WithIncludePrivate]). Expired cookies are included and
+// counted, unless excluded with [WithIncludeExpired].
+func Stats(ctx context.Context, profile string, opts ...Option) (*models.Stats, error) {
+	o := newOptions(append([]Option{WithIncludeExpired(true)}, opts...)...)
+	file, err := o.profileFile(profile)
+	if err != nil {
+		return nil, err
+	}
+	src, closeSrc, err := o.open(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSrc()
+	res, err := o.rows(ctx, src, file, o.host)
+	if err != nil {
+		return nil, err
+	}
+	return models.StatsOf(res, o.now().Unix()), nil
+}
@@ -0,0 +1,55 @@
+package ffcookies
+
+import (
+	"context"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/kenshaw/ffcookies/models"
+)
+
+func TestStats(t *testing.T) {
+	s, err := Stats(context.Background(), "", WithCookieFile(testDB), WithHost("stats.test"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, test := range []struct {
+		name   string
+		v, exp int
+	}{
+		{"total", s.Total, 4},
+		{"secure", s.Secure, 3},
+		{"http only", s.HTTPOnly, 2},
+		{"session", s.Session, 1},
+		{"expired", s.Expired, 1},
+		{"domains", s.Domains, 2},
+	} {
+		if test.v != test.exp {
+			t.Errorf("expected %s %d, got: %d", test.name, test.exp, test.v)
+		}
+	}
+	exp := map[models.SameSite]int{
+		models.SameSiteNone:   1,
+		models.SameSiteLax:    1,
+		models.SameSiteStrict: 1,
+		models.SameSiteUnset:  1,
+	}
+	if !maps.Equal(s.SameSite, exp) {
+		t.Errorf("expected same site %v, got: %v", exp, s.SameSite)
+	}
+	if exp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !s.OldestCreation.Equal(exp) {
+		t.Errorf("expected oldest creation %v, got: %v", exp, s.OldestCreation)
+	}
+	if exp := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC); !s.NewestCreation.Equal(exp) {
+		t.Errorf("expected newest creation %v, got: %v", exp, s.NewestCreation)
+	}
+	// private browsing cookies
+	s, err = Stats(context.Background(), "", WithCookieFile(testDB), WithHost("stats.test"), WithIncludePrivate(true))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s.Total != 5 {
+		t.Errorf("expected total 5 including private cookies, got: %d", s.Total)
+	}
+}
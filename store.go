@@ -0,0 +1,127 @@
+package ffcookies
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// Store reads cookies from a Firefox profile's cookie database, keeping the
+// database open between reads and reusing prepared statements. A Store is safe
+// for concurrent use.
+//
+// Unlike [Reader], a Store does not cache cookies, and does not reopen the
+// database when it changes.
+type Store struct {
+	o     *options
+	file  string
+	src   source
+	close func() error
+}
+
+// OpenContext opens a store for the provided Firefox profile name, or the
+// default Firefox profile. The store should be closed when no longer needed.
+func OpenContext(ctx context.Context, profile string, opts ...Option) (*Store, error) {
+	o := newOptions(opts...)
+	file, err := o.profileFile(profile)
+	if err != nil {
+		return nil, err
+	}
+	src, closeSrc, err := o.open(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{
+		o:     o,
+		file:  file,
+		src:   src,
+		close: closeSrc,
+	}
+	// reuse prepared statements when opened with a sqlite3 driver
+	switch x := src.(type) {
+	case dbSource:
+		stmts := newStmtDB(x)
+		s.src, s.close = dbSource{stmts}, func() error {
+			return errors.Join(stmts.closeStmts(), closeSrc())
+		}
+	case *copySource:
+		// only the database, and not its copy (see LockCopy)
+		if db, ok := x.src.(dbSource); ok {
+			stmts := newStmtDB(db)
+			x.src, s.close = dbSource{stmts}, func() error {
+				return errors.Join(stmts.closeStmts(), closeSrc())
+			}
+		}
+	}
+	return s, nil
+}
+
+// Open opens a store for the provided Firefox profile name, or the default
+// Firefox profile.
+func Open(profile string, opts ...Option) (*Store, error) {
+	return OpenContext(context.Background(), profile, opts...)
+}
+
+// Cookies reads the cookies for the host.
+func (s *Store) Cookies(ctx context.Context, host string) ([]*http.Cookie, error) {
+	return s.o.read(ctx, s.src, s.file, host)
+}
+
+// Close closes the store.
+func (s *Store) Close() error {
+	return s.close()
+}
+
+// stmtDB is a database that prepares each query once, reusing the prepared
+// statement for subsequent queries.
+type stmtDB struct {
+	*sql.DB
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+// newStmtDB creates a statement caching database for the source's database.
+func newStmtDB(src dbSource) *stmtDB {
+	return &stmtDB{
+		DB:    src.db.(*sql.DB),
+		stmts: make(map[string]*sql.Stmt),
+	}
+}
+
+// QueryContext satisfies the [models.DB] interface.
+func (db *stmtDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	stmt, err := db.stmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+// stmt returns the prepared statement for the query, preparing it if needed.
+func (db *stmtDB) stmt(ctx context.Context, query string) (*sql.Stmt, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if stmt, ok := db.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	db.stmts[query] = stmt
+	return stmt, nil
+}
+
+// closeStmts closes the prepared statements.
+func (db *stmtDB) closeStmts() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var errs []error
+	for query, stmt := range db.stmts {
+		errs = append(errs, stmt.Close())
+		delete(db.stmts, query)
+	}
+	return errors.Join(errs...)
+}
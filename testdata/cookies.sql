@@ -0,0 +1,124 @@
+-- cookies.sql is the test cookie database, using the moz_cookies schema at
+-- schema version 16. Regenerate cookies.sqlite with gen.sh.
+--
+-- Times are relative to 2025-01-01 00:00:00 UTC (1735689600). Rows are grouped
+-- by host, with each test reading the hosts it needs.
+
+PRAGMA user_version = 16;
+
+CREATE TABLE moz_cookies (
+  id INTEGER PRIMARY KEY,
+  originAttributes TEXT NOT NULL DEFAULT '',
+  name TEXT,
+  value TEXT,
+  host TEXT,
+  path TEXT,
+  expiry INTEGER,
+  lastAccessed INTEGER,
+  creationTime INTEGER,
+  isSecure INTEGER,
+  isHttpOnly INTEGER,
+  inBrowserElement INTEGER DEFAULT 0,
+  sameSite INTEGER DEFAULT 0,
+  rawSameSite INTEGER DEFAULT 0,
+  schemeMap INTEGER DEFAULT 0,
+  isPartitionedAttributeSet INTEGER DEFAULT 0,
+  CONSTRAINT moz_uniqueid UNIQUE (name, host, path, originAttributes)
+);
+
+-- accessed.test: accessed 2024-06-01 and 2025-06-01
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap) VALUES
+  ('', 'old', '1', '.accessed.test', '/', 4102444800, 1717200000000000, 1717200000000000, 1, 0, 0, 0, 2),
+  ('', 'new', '2', '.accessed.test', '/', 4102444800, 1748736000000000, 1748736000000000, 1, 0, 0, 0, 2);
+
+-- emptyname.test: rows with null and empty names
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap) VALUES
+  ('', NULL, 'null', '.emptyname.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2),
+  ('', '', 'empty', '.emptyname.test', '/a', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2),
+  ('', 'ok', '1', '.emptyname.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2);
+
+-- hostonly.test: host-only and domain cookies
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap) VALUES
+  ('', 'host', '1', 'hostonly.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2),
+  ('', 'domain', '2', '.hostonly.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2);
+
+-- export.test: live, expired (2000-01-01), session and http only cookies
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap) VALUES
+  ('', 'live', '1', '.export.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2),
+  ('', 'expired', '2', '.export.test', '/', 946684800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2),
+  ('', 'session', '3', 'www.export.test', '/a', 0, 1735689600000000, 1735689600000000, 0, 0, 0, 0, 1),
+  ('', 'httponly', '4', '.export.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 1, 0, 0, 2);
+
+-- stats.test: 4 cookies (3 secure, 2 http only, 1 session, 1 expired, 2
+-- hosts, one of each SameSite value) created from 2024-01-01 to 2024-12-01,
+-- and a private browsing cookie
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap) VALUES
+  ('', 'a', '1', '.stats.test', '/', 4102444800, 1735689600000000, 1704067200000000, 1, 1, 0, 0, 2),
+  ('', 'b', '2', '.stats.test', '/', 0, 1735689600000000, 1717200000000000, 1, 0, 1, 1, 2),
+  ('', 'c', '3', 'www.stats.test', '/', 946684800, 1735689600000000, 1709251200000000, 0, 0, 2, 2, 1),
+  ('', 'd', '4', 'www.stats.test', '/', 4102444800, 1735689600000000, 1733011200000000, 1, 1, 256, 256, 2),
+  ('^privateBrowsingId=1', 'e', '5', '.stats.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 1, 1, 1, 2);
+
+-- home.arpa: domain cookie for a public suffix used on intranets
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap) VALUES
+  ('', 'sso', '1', '.home.arpa', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2);
+
+-- container.test: a cookie in two containers, a cookie in the default
+-- container and a container, and a partitioned cookie
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap) VALUES
+  ('^userContextId=1', 'k', 'c1', '.container.test', '/', 4102444800, 1717200000000000, 1717200000000000, 1, 0, 0, 0, 2),
+  ('^userContextId=2', 'k', 'c2', '.container.test', '/', 4102444800, 1733011200000000, 1717200000000000, 1, 0, 0, 0, 2),
+  ('', 'd', 'default', '.container.test', '/', 4102444800, 1717200000000000, 1717200000000000, 1, 0, 0, 0, 2),
+  ('^userContextId=1', 'd', 'c1', '.container.test', '/', 4102444800, 1733011200000000, 1717200000000000, 1, 0, 0, 0, 2),
+  ('^partitionKey=%28https%2Cother.test%29', 'p', 'partitioned', '.container.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2);
+
+-- samesite.test: SameSite=None cookies with and without Secure
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap) VALUES
+  ('', 'legacy', '1', '.samesite.test', '/', 4102444800, 1735689600000000, 1735689600000000, 0, 0, 0, 0, 1),
+  ('', 'secure', '2', '.samesite.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2);
+
+-- widget.test: an unpartitioned cookie, and partitioned cookies keyed to
+-- widget.test and other.test
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap, isPartitionedAttributeSet) VALUES
+  ('', 'plain', '1', '.widget.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2, 0),
+  ('^partitionKey=%28https%2Cwidget.test%29', 'chips', '2', '.widget.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2, 1),
+  ('^partitionKey=%28https%2Cother.test%29', 'other', '3', '.widget.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2, 1);
+
+-- clock.test: cookies expiring 2025-06-01 and 2100-01-01, and a session
+-- cookie
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap) VALUES
+  ('', 'soon', '1', '.clock.test', '/', 1748736000, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2),
+  ('', 'later', '2', '.clock.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2),
+  ('', 'session', '3', '.clock.test', '/', 0, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2);
+
+-- example.co.uk: cookies for the registrable domain, its subdomains, and other
+-- domains of the co.uk public suffix
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap) VALUES
+  ('', 'app', '1', 'app.example.co.uk', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2),
+  ('', 'site', '2', '.example.co.uk', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2),
+  ('', 'www', '3', 'www.example.co.uk', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2),
+  ('', 'lookalike', '4', '.notexample.co.uk', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2),
+  ('', 'other', '5', '.other.co.uk', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2);
+
+-- decode.test: percent-encoded values
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap) VALUES
+  ('', 'space', 'a%20b', '.decode.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2),
+  ('', 'double', 'a%2520b', '.decode.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2),
+  ('', 'invalid', '100%', '.decode.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2);
+
+-- 127.0.0.1: a host-only cookie for the loopback address
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap) VALUES
+  ('', 'rt', '1', '127.0.0.1', '/', 4102444800, 1735689600000000, 1735689600000000, 0, 0, 0, 0, 1);
+
+-- null.test: a cookie with a NULL value and path
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap) VALUES
+  ('', 'null', NULL, '.null.test', NULL, 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2);
+
+-- exact.test: cookies for the parent domain and a subdomain
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap) VALUES
+  ('', 'parent', '1', '.exact.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2),
+  ('', 'www', '2', 'www.exact.test', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2);
+
+-- ::1: a host-only cookie for the IPv6 loopback address
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite, rawSameSite, schemeMap) VALUES
+  ('', 'v6', '1', '::1', '/', 4102444800, 1735689600000000, 1735689600000000, 1, 0, 0, 0, 2);
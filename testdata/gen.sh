@@ -0,0 +1,10 @@
+#!/bin/bash
+
+# gen.sh regenerates the test cookie database from cookies.sql.
+
+SRC=$(realpath $(cd -P "$(dirname "${BASH_SOURCE[0]}")" && pwd))
+
+set -ex
+
+rm -f $SRC/cookies.sqlite
+sqlite3 $SRC/cookies.sqlite < $SRC/cookies.sql
@@ -0,0 +1,123 @@
+package ffcookies
+
+import "net/http"
+
+// RoundTripper is a http.RoundTripper that adds the cookies of a Firefox
+// profile to each request. Cookies are read once per scheme and host, and
+// are added as-is (see [AddCookies]).
+type RoundTripper struct {
+	base http.RoundTripper
+	r    *Reader
+	// owned is true when the reader was created by the round tripper
+	owned bool
+}
+
+// Transport creates a http.RoundTripper that adds the cookies of the provided
+// Firefox profile name (or the default Firefox profile) that Firefox would
+// send for the request's url (see [CookiesForURL]), before passing the
+// request to base. When base is nil, [http.DefaultTransport] is used.
+//
+// The cookies are read with a [Reader] created for the profile and read
+// options, which is closed when the round tripper is closed. Use
+// [Reader.Transport] to share a reader with other uses, or to control when it
+// is closed:
+//
+//	rt := ffcookies.Transport(nil, "")
+//	defer rt.Close()
+//	cl := &http.Client{Transport: rt}
+func Transport(base http.RoundTripper, profile string, opts ...Option) *RoundTripper {
+	return &RoundTripper{
+		base:  base,
+		r:     NewReader(profile, opts...),
+		owned: true,
+	}
+}
+
+// Transport creates a http.RoundTripper that adds the cookies read by the
+// reader, as with [Transport].
+//
+// The reader is not closed by the round tripper, and should be closed by the
+// caller once the round tripper is no longer used:
+//
+//	r := ffcookies.NewReader("")
+//	defer r.Close()
+//	cl := &http.Client{Transport: r.Transport(nil)}
+func (r *Reader) Transport(base http.RoundTripper) *RoundTripper {
+	return &RoundTripper{
+		base: base,
+		r:    r,
+	}
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	cookies, err := t.r.CookiesForURL(req.Context(), req.URL)
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+	if len(cookies) != 0 {
+		req = req.Clone(req.Context())
+		AddCookies(req, cookies...)
+	}
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// Close closes the reader created by [Transport]. Readers passed to
+// [Reader.Transport] are not closed.
+func (t *RoundTripper) Close() error {
+	if !t.owned {
+		return nil
+	}
+	return t.r.Close()
+}
+
+// InjectCookies creates a middleware that adds the cookies of the provided
+// Firefox profile name (or the default Firefox profile) to each incoming
+// request, as Firefox would send them to the request's host. Useful for local
+// development proxies. Cookies are read once per scheme and host.
+//
+// Requests with a relative url are treated as being for the request's Host,
+// using https when the request was received over TLS.
+//
+// The cookies are read with a [Reader] created for the profile and read
+// options, which is kept open for the lifetime of the middleware. Use
+// [Reader.InjectCookies] to control when the reader is closed.
+func InjectCookies(profile string, opts ...Option) func(http.Handler) http.Handler {
+	return NewReader(profile, opts...).InjectCookies()
+}
+
+// InjectCookies creates a middleware that adds the cookies read by the reader
+// to each incoming request, as with [InjectCookies].
+//
+// The reader is not closed by the middleware, and should be closed by the
+// caller once the middleware is no longer used.
+func (r *Reader) InjectCookies() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			u := *req.URL
+			if u.Host == "" {
+				u.Scheme, u.Host = "http", req.Host
+				if req.TLS != nil {
+					u.Scheme = "https"
+				}
+			}
+			cookies, err := r.CookiesForURL(req.Context(), &u)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			if len(cookies) != 0 {
+				req = req.Clone(req.Context())
+				AddCookies(req, cookies...)
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package ffcookies
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransport(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = io.WriteString(w, req.Header.Get("Cookie"))
+	}))
+	defer s.Close()
+	rt := Transport(nil, "", WithCookieFile(testDB))
+	cl := &http.Client{Transport: rt}
+	if s, exp := get(t, cl, s.URL), "rt=1"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+	// the reader created by the round tripper is closed
+	if err := rt.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if rt.r.src != nil {
+		t.Errorf("expected reader to be closed")
+	}
+}
+
+func TestReaderTransport(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = io.WriteString(w, req.Header.Get("Cookie"))
+	}))
+	defer s.Close()
+	r := NewReader("", WithCookieFile(testDB))
+	defer r.Close()
+	rt := r.Transport(nil)
+	cl := &http.Client{Transport: rt}
+	if s, exp := get(t, cl, s.URL), "rt=1"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+	// the reader is not closed by the round tripper
+	if err := rt.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if r.src == nil {
+		t.Errorf("expected reader to not be closed")
+	}
+}
+
+func TestInjectCookies(t *testing.T) {
+	s := httptest.NewServer(InjectCookies("", WithCookieFile(testDB))(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = io.WriteString(w, req.Header.Get("Cookie"))
+	})))
+	defer s.Close()
+	if s, exp := get(t, s.Client(), s.URL), "rt=1"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+}
+
+func TestReaderInjectCookies(t *testing.T) {
+	r := NewReader("", WithCookieFile(testDB))
+	defer r.Close()
+	s := httptest.NewServer(r.InjectCookies()(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = io.WriteString(w, req.Header.Get("Cookie"))
+	})))
+	defer s.Close()
+	if s, exp := get(t, s.Client(), s.URL), "rt=1"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+}
+
+// get returns the body of the response to a GET request for the url.
+func get(t *testing.T, cl *http.Client, urlstr string) string {
+	t.Helper()
+	res, err := cl.Get(urlstr)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer res.Body.Close()
+	buf, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return string(buf)
+}
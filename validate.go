@@ -0,0 +1,114 @@
+package ffcookies
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Validate checks the cookies against net/http's cookie rules and some basic
+// domain sanity checks, returning an error for each invalid cookie. The
+// cookies are not modified.
+//
+// SameSite=None cookies without the Secure attribute are reported with
+// [ErrInsecureSameSiteNone], as modern browsers reject them.
+func Validate(cookies []*http.Cookie) []error {
+	var errs []error
+	for i, cookie := range cookies {
+		if err := validateCookie(cookie); err != nil {
+			errs = append(errs, fmt.Errorf("cookie %d: %w", i, err))
+		}
+	}
+	return errs
+}
+
+// validateCookie validates a single cookie.
+func validateCookie(cookie *http.Cookie) error {
+	if cookie == nil {
+		return errors.New("nil cookie")
+	}
+	var errs []error
+	if err := cookie.Valid(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateDomain(cookie.Domain); err != nil {
+		errs = append(errs, err)
+	}
+	if insecureSameSiteNone(cookie) {
+		errs = append(errs, ErrInsecureSameSiteNone)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%q: %w", cookie.Name, err)
+	}
+	return nil
+}
+
+// validateDomain checks that the cookie domain is a plausible host name. An
+// empty domain (a host-only cookie, as set by a server) is valid.
+func validateDomain(domain string) error {
+	host := strings.TrimPrefix(domain, ".")
+	switch {
+	case domain == "":
+		return nil
+	case host == "":
+		return fmt.Errorf("invalid domain %q", domain)
+	case len(host) > 253:
+		return fmt.Errorf("domain %q too long", domain)
+	}
+	for _, label := range strings.Split(host, ".") {
+		switch {
+		case label == "":
+			return fmt.Errorf("domain %q has an empty label", domain)
+		case len(label) > 63:
+			return fmt.Errorf("domain %q has a label that is too long", domain)
+		}
+		for _, r := range label {
+			if r <= ' ' || r == 0x7f || strings.ContainsRune(`"();,/\<>@[]{}=?`, r) {
+				return fmt.Errorf("domain %q contains invalid character %q", domain, r)
+			}
+		}
+	}
+	return nil
+}
+
+// insecureSameSiteNone returns true when the cookie is SameSite=None without
+// the Secure attribute.
+func insecureSameSiteNone(cookie *http.Cookie) bool {
+	return cookie.SameSite == http.SameSiteNoneMode && !cookie.Secure
+}
+
+// SameSiteNonePolicy is the policy for handling SameSite=None cookies without
+// the Secure attribute, which Firefox may have stored for legacy cookies.
+type SameSiteNonePolicy int
+
+// SameSiteNonePolicy values.
+const (
+	// SameSiteNoneKeep keeps the cookies as-is.
+	SameSiteNoneKeep SameSiteNonePolicy = iota
+	// SameSiteNoneFlag returns an error wrapping [ErrInsecureSameSiteNone]
+	// listing the cookies.
+	SameSiteNoneFlag
+	// SameSiteNoneFix sets the Secure attribute on the cookies.
+	SameSiteNoneFix
+)
+
+// apply applies the policy to the cookies.
+func (policy SameSiteNonePolicy) apply(cookies []*http.Cookie) error {
+	var names []string
+	for _, cookie := range cookies {
+		if !insecureSameSiteNone(cookie) {
+			continue
+		}
+		switch policy {
+		case SameSiteNoneFlag:
+			names = append(names, fmt.Sprintf("%s (%s%s)", cookie.Name, cookie.Domain, cookie.Path))
+		case SameSiteNoneFix:
+			cookie.Secure = true
+		}
+	}
+	if len(names) != 0 {
+		return fmt.Errorf("%w: %s", ErrInsecureSameSiteNone, strings.Join(names, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package ffcookies
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	cookies := []*http.Cookie{
+		{Name: "a", Value: "1", Domain: ".example.com", Path: "/"},
+		{Name: "b c", Value: "2", Domain: "example.com", Path: "/"},
+		{Name: "d", Value: "3", Domain: "www.example.com", Path: "/"},
+	}
+	errs := Validate(cookies)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got: %v", errs)
+	}
+	if s := errs[0].Error(); !strings.HasPrefix(s, `cookie 1: "b c": `) {
+		t.Errorf("expected error for cookie 1, got: %s", s)
+	}
+	if cookies[1].Name != "b c" {
+		t.Errorf("expected cookie to not be modified, got: %q", cookies[1].Name)
+	}
+}
+
+func TestValidateDomain(t *testing.T) {
+	tests := []struct {
+		domain string
+		valid  bool
+	}{
+		{"example.com", true},
+		{".example.com", true},
+		{"localhost", true},
+		{"", true},
+		{".", false},
+		{"example..com", false},
+		{"exa mple.com", false},
+		{"example.com/", false},
+		{strings.Repeat("a", 64) + ".com", false},
+	}
+	for _, test := range tests {
+		t.Run(test.domain, func(t *testing.T) {
+			err := validateDomain(test.domain)
+			switch {
+			case test.valid && err != nil:
+				t.Errorf("expected no error, got: %v", err)
+			case !test.valid && err == nil:
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func TestValidateSameSiteNone(t *testing.T) {
+	cookies := []*http.Cookie{
+		{Name: "a", Value: "1", Domain: ".example.com", Path: "/", SameSite: http.SameSiteNoneMode},
+		{Name: "b", Value: "2", Domain: ".example.com", Path: "/", SameSite: http.SameSiteNoneMode, Secure: true},
+	}
+	errs := Validate(cookies)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got: %v", errs)
+	}
+	if !errors.Is(errs[0], ErrInsecureSameSiteNone) {
+		t.Errorf("expected insecure SameSite=None error, got: %v", errs[0])
+	}
+}
+
+func TestWithSameSiteNonePolicy(t *testing.T) {
+	tests := []struct {
+		policy SameSiteNonePolicy
+		err    bool
+		secure bool
+	}{
+		{SameSiteNoneKeep, false, false},
+		{SameSiteNoneFlag, true, false},
+		{SameSiteNoneFix, false, true},
+	}
+	for _, test := range tests {
+		t.Run(strconv.Itoa(int(test.policy)), func(t *testing.T) {
+			cookies, err := ReadFile(testDB, "samesite.test", WithSameSiteNonePolicy(test.policy))
+			switch {
+			case test.err && !errors.Is(err, ErrInsecureSameSiteNone):
+				t.Fatalf("expected insecure SameSite=None error, got: %v", err)
+			case test.err:
+				if !strings.Contains(err.Error(), "legacy (.samesite.test/)") || strings.Contains(err.Error(), "secure") {
+					t.Errorf("expected error listing only the legacy cookie, got: %v", err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if len(cookies) != 2 {
+				t.Fatalf("expected 2 cookies, got: %d", len(cookies))
+			}
+			for _, cookie := range cookies {
+				if cookie.SameSite != http.SameSiteNoneMode {
+					t.Errorf("expected SameSite=None, got: %v", cookie.SameSite)
+				}
+				if exp := cookie.Name == "secure" || test.secure; cookie.Secure != exp {
+					t.Errorf("expected %s secure %t, got: %t", cookie.Name, exp, cookie.Secure)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package ffcookies
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// AsValues returns the cookies as url.Values, mapping each cookie name to its
+// values. Cookies with the same name (for example, for different domains or
+// paths) are added as multiple values, in the order of the cookies.
+func AsValues(cookies []*http.Cookie) url.Values {
+	v := make(url.Values)
+	for _, cookie := range cookies {
+		v.Add(cookie.Name, cookie.Value)
+	}
+	return v
+}
@@ -0,0 +1,27 @@
+package ffcookies
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestAsValues(t *testing.T) {
+	cookies := []*http.Cookie{
+		{Name: "a", Value: "1", Domain: ".example.com", Path: "/"},
+		{Name: "b", Value: "2", Domain: ".example.com", Path: "/"},
+		{Name: "a", Value: "3", Domain: "www.example.com", Path: "/"},
+		{Name: "a", Value: "4", Domain: ".example.com", Path: "/app"},
+	}
+	exp := url.Values{
+		"a": {"1", "3", "4"},
+		"b": {"2"},
+	}
+	if v := AsValues(cookies); !reflect.DeepEqual(v, exp) {
+		t.Errorf("expected %v, got: %v", exp, v)
+	}
+	if v := AsValues(nil); v == nil || len(v) != 0 {
+		t.Errorf("expected empty values, got: %v", v)
+	}
+}